@@ -31,6 +31,7 @@ func initApp() {
 	app.HideVersion = true // we have a command to print the version
 	app.Commands = []*cli.Command{
 		utils.VersionCommand,
+		rescanCommand,
 	}
 	app.Flags = []cli.Flag{
 		utils.ConfigFileFlag,
@@ -57,27 +58,40 @@ func run(ctx *cli.Context) error {
 		return fmt.Errorf("invalid command: %q", ctx.Args().Get(0))
 	}
 
+	initRouterServer := loadConfig(ctx)
+
+	routersdk.StartEndpoint()
+	server.StartAPIServer()
+
+	initRouterBridges(initRouterServer)
+	bridge.StartReloadRouterConfigTask()
+
+	routersdk.InitAfterLoad()
+	routersdk.StartScanner(routersdk.BridgeInstance)
+	routersdk.StartSequenceReconciler(routersdk.BridgeInstance)
+	routersdk.StartEventSubscriber(routersdk.BridgeInstance)
+	routersdk.StartMetricsUpdater(routersdk.BridgeInstance)
+
+	utils.TopWaitGroup.Wait()
+	return nil
+}
+
+func loadConfig(ctx *cli.Context) (initRouterServer bool) {
 	configFile := utils.GetConfigFilePath(ctx)
 	config1 := config.LoadConfig(configFile, true)
 
-	initRouterServer := config1.InitRouterServer
+	initRouterServer = config1.InitRouterServer
 	routerConfigFile := config1.RouterConfigFile
 
 	config2 := params.LoadRouterConfig(routerConfigFile, initRouterServer, true)
 	tokens.InitRouterSwapType(config2.SwapType)
+	return initRouterServer
+}
 
-	routersdk.StartEndpoint()
-	server.StartAPIServer()
-
+func initRouterBridges(initRouterServer bool) {
 	bridge.IsWrapperMode = true
 	filterChainIds := make([]string, 2)
 	filterChainIds = append(filterChainIds, config.GetServerConfig().RouterConfigChainId)
 	filterChainIds = append(filterChainIds, config.GetServerConfig().ChainID)
 	bridge.InitRouterBridgesWithFilterChain(initRouterServer, filterChainIds)
-	bridge.StartReloadRouterConfigTask()
-
-	routersdk.InitAfterLoad()
-
-	utils.TopWaitGroup.Wait()
-	return nil
 }
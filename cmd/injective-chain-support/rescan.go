@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	routersdk "github.com/anyswap/RouterSDK-injective/sdk"
+	"github.com/urfave/cli/v2"
+)
+
+var rescanCommand = &cli.Command{
+	Action:    rescan,
+	Name:      "rescan",
+	Usage:     "rescan deposits in height range",
+	ArgsUsage: " ",
+	Description: `
+rescan blocks in height range [start, end) and verify txs paying the router mpc,
+write the result of each found deposit as a json line to the output file.
+end defaults to the current stable height + 1.
+`,
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		utils.StartHeightFlag,
+		utils.EndHeightFlag,
+		utils.JobsFlag,
+		utils.OutputFileFlag,
+		utils.VerbosityFlag,
+		utils.JSONFormatFlag,
+		utils.ColorFormatFlag,
+	},
+}
+
+func rescan(ctx *cli.Context) error {
+	utils.SetLogger(ctx)
+
+	start := ctx.Uint64(utils.StartHeightFlag.Name)
+	end := ctx.Uint64(utils.EndHeightFlag.Name)
+	jobs := ctx.Uint64(utils.JobsFlag.Name)
+
+	output := os.Stdout
+	if outputFile := ctx.String(utils.OutputFileFlag.Name); outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	initRouterServer := loadConfig(ctx)
+	routersdk.StartEndpoint()
+	initRouterBridges(initRouterServer)
+	routersdk.InitAfterLoad()
+
+	b := routersdk.BridgeInstance
+	if end == 0 {
+		stableHeight, err := b.GetStableHeight()
+		if err != nil {
+			return err
+		}
+		end = stableHeight + 1
+	}
+	if start >= end {
+		return fmt.Errorf("wrong height range [%v, %v)", start, end)
+	}
+	log.Info("rescan start", "start", start, "end", end, "jobs", jobs)
+
+	var matched, rejected, failed int
+	encoder := json.NewEncoder(output)
+	b.Rescan(start, end, int(jobs), func(result *routersdk.ScanResult) {
+		switch {
+		case result.Matched:
+			matched++
+		case result.TxHash == "":
+			failed++
+		default:
+			rejected++
+		}
+		if err := encoder.Encode(result); err != nil {
+			log.Warn("write rescan result failed", "height", result.Height, "txHash", result.TxHash, "err", err)
+		}
+	})
+
+	log.Info("rescan finished", "start", start, "end", end, "matched", matched, "rejected", rejected, "failedBlocks", failed)
+	return nil
+}
@@ -141,6 +141,12 @@ var (
 		Name:  "memo",
 		Usage: "memo text",
 	}
+	// OutputFileFlag -o|--output
+	OutputFileFlag = &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "output file (default is stdout)",
+	}
 
 	// CommonLogFlags common log flags
 	CommonLogFlags = []cli.Flag{
@@ -25,5 +25,144 @@ func (c *ServerConfig) CheckConfig() (err error) {
 		}
 		return fmt.Errorf("wrong session token: %v", tok.Token)
 	}
+	for _, tok := range c.SessionTokens {
+		for _, role := range tok.Roles {
+			switch role {
+			case RoleReadOnly, RoleVerifier, RoleSigner, RoleAdmin:
+			default:
+				return fmt.Errorf("session token of user %v has unknown role: %v", tok.User, role)
+			}
+		}
+	}
+	if c.AuthConfig != nil {
+		c.AuthConfig.CheckConfig()
+	}
+	if c.ScanConfig != nil {
+		if err = c.ScanConfig.CheckConfig(); err != nil {
+			return err
+		}
+	}
+	if c.BroadcastConfig != nil {
+		c.BroadcastConfig.CheckConfig()
+	}
+	if c.QuorumConfig != nil {
+		if err = c.QuorumConfig.CheckConfig(); err != nil {
+			return err
+		}
+	}
+	if c.QueryConfig != nil {
+		if err = c.QueryConfig.CheckConfig(); err != nil {
+			return err
+		}
+	}
+	if c.LightClientConfig != nil {
+		if err = c.LightClientConfig.CheckConfig(); err != nil {
+			return err
+		}
+		if c.LightClientConfig.Enable && len(c.GatewayConfig.GRPCAPIAddress) == 0 {
+			return fmt.Errorf("light client requires 'GRPCAPIAddress' (tendermint rpc) in gateway config")
+		}
+	}
+	if c.MetricsConfig != nil {
+		c.MetricsConfig.CheckConfig()
+	}
+	if c.StatsConfig != nil {
+		c.StatsConfig.CheckConfig()
+	}
+	if c.SubscribeConfig != nil && c.SubscribeConfig.Enable && len(c.GatewayConfig.GRPCAPIAddress) == 0 {
+		return fmt.Errorf("subscription requires 'GRPCAPIAddress' (tendermint rpc) in gateway config")
+	}
+	return nil
+}
+
+// CheckConfig check auth config
+func (c *AuthConfig) CheckConfig() {
+	if c.MaxDelaySeconds == 0 {
+		c.MaxDelaySeconds = 120
+	}
+	if c.MaxFutureSeconds == 0 {
+		c.MaxFutureSeconds = 10
+	}
+}
+
+// CheckConfig check scan config
+func (c *ScanConfig) CheckConfig() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.CheckpointFile == "" {
+		return fmt.Errorf("scan config must specify 'CheckpointFile'")
+	}
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = 5
+	}
 	return nil
 }
+
+// CheckConfig check broadcast config
+func (c *BroadcastConfig) CheckConfig() {
+	if c.CommitTimeoutSeconds == 0 {
+		c.CommitTimeoutSeconds = 60
+	}
+}
+
+// CheckConfig check quorum config
+func (c *QuorumConfig) CheckConfig() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Endpoints == 0 {
+		c.Endpoints = 2
+	}
+	if c.Endpoints < 2 {
+		return fmt.Errorf("quorum config 'Endpoints' must be at least 2")
+	}
+	return nil
+}
+
+// CheckConfig check query config
+func (c *QueryConfig) CheckConfig() error {
+	if c.DefaultTimeoutSeconds < 0 {
+		return fmt.Errorf("query config has negative 'DefaultTimeoutSeconds'")
+	}
+	for method, seconds := range c.MethodTimeoutSeconds {
+		if seconds < 0 {
+			return fmt.Errorf("query config has negative timeout of method %v", method)
+		}
+	}
+	return nil
+}
+
+// CheckConfig check light client config
+func (c *LightClientConfig) CheckConfig() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.TrustedHeight <= 0 {
+		return fmt.Errorf("light client config must specify 'TrustedHeight'")
+	}
+	if hash := common.FromHex(c.TrustedHash); len(hash) != 32 {
+		return fmt.Errorf("light client config has wrong 'TrustedHash': %v", c.TrustedHash)
+	}
+	if c.TrustingPeriodHours == 0 {
+		c.TrustingPeriodHours = 336 // 14 days
+	}
+	return nil
+}
+
+// CheckConfig check metrics config
+func (c *MetricsConfig) CheckConfig() {
+	if c.BalanceIntervalSeconds == 0 {
+		c.BalanceIntervalSeconds = 60
+	}
+}
+
+// CheckConfig check stats config
+func (c *StatsConfig) CheckConfig() {
+	if c.RetentionDays == 0 {
+		c.RetentionDays = 30
+	}
+	if c.SaveIntervalSeconds == 0 {
+		c.SaveIntervalSeconds = 60
+	}
+}
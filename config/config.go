@@ -9,6 +9,7 @@ import (
 var (
 	serverConfig     = &ServerConfig{}
 	serverConfigFile string
+	configLoaded     bool
 )
 
 // GetServerConfig get server config
@@ -28,8 +29,89 @@ type ServerConfig struct {
 	AllowedOrigins   []string        `toml:",omitempty" json:",omitempty"`
 	MaxRequestsLimit int             `toml:",omitempty" json:",omitempty"`
 	SessionTokens    []*SessionToken `toml:",omitempty" json:",omitempty"`
+	AuthConfig       *AuthConfig     `toml:",omitempty" json:",omitempty"`
 
 	GatewayConfig *tokens.GatewayConfig
+
+	ScanConfig      *ScanConfig      `toml:",omitempty" json:",omitempty"`
+	BroadcastConfig *BroadcastConfig `toml:",omitempty" json:",omitempty"`
+	QuorumConfig    *QuorumConfig    `toml:",omitempty" json:",omitempty"`
+	QueryConfig     *QueryConfig     `toml:",omitempty" json:",omitempty"`
+
+	LightClientConfig *LightClientConfig `toml:",omitempty" json:",omitempty"`
+	SubscribeConfig   *SubscribeConfig   `toml:",omitempty" json:",omitempty"`
+	MetricsConfig     *MetricsConfig     `toml:",omitempty" json:",omitempty"`
+	StatsConfig       *StatsConfig       `toml:",omitempty" json:",omitempty"`
+	SwapMemoConfig    *SwapMemoConfig    `toml:",omitempty" json:",omitempty"`
+}
+
+// ScanConfig block scanner config
+type ScanConfig struct {
+	Enable          bool
+	StartHeight     uint64 // used if checkpoint file not exist
+	CheckpointFile  string
+	IntervalSeconds uint64 `toml:",omitempty" json:",omitempty"`
+	RouterServerURL string `toml:",omitempty" json:",omitempty"` // register swaps to router server
+	WebhookURL      string `toml:",omitempty" json:",omitempty"` // post swap infos
+}
+
+// BroadcastConfig broadcast tx config
+type BroadcastConfig struct {
+	WaitCommitted        bool   // wait until the sent tx is included in block
+	CommitTimeoutSeconds uint64 `toml:",omitempty" json:",omitempty"`
+}
+
+// QuorumConfig quorum reads config of verification
+type QuorumConfig struct {
+	Enable    bool
+	Endpoints int // number of distinct nodes (hosts) which must agree
+}
+
+// QueryConfig timeouts of queries to gateway nodes
+type QueryConfig struct {
+	DefaultTimeoutSeconds int
+	MethodTimeoutSeconds  map[string]int // method name (eg. `GetTransactionByHash`) to timeout
+}
+
+// LightClientConfig tendermint light client config of verifying deposit txs
+type LightClientConfig struct {
+	Enable              bool
+	TrustedHeight       int64
+	TrustedHash         string   // hex of the trusted block hash
+	TrustingPeriodHours uint64   // should be significantly less than the unbonding period
+	Witnesses           []string // tendermint rpc urls, default is `GRPCAPIAddress` except the primary (the first one)
+	DBDir               string   // directory of trusted headers db, use memory db if empty
+}
+
+// SubscribeConfig tendermint websocket subscription of new blocks and deposits
+type SubscribeConfig struct {
+	Enable bool
+}
+
+// MetricsConfig prometheus metrics config
+type MetricsConfig struct {
+	Enable                 bool
+	Public                 bool   // serve metrics without session token
+	BalanceIntervalSeconds uint64 // interval of updating mpc balances
+}
+
+// SwapMemoConfig json swap memo config
+type SwapMemoConfig struct {
+	AllowedCallProxies map[string][]string // dest chain id to allowed call proxies, call swaps are rejected if not allowed
+}
+
+// StatsConfig rpc call statistics config
+type StatsConfig struct {
+	File                string `toml:",omitempty" json:",omitempty"` // persist stats to file, in memory only if empty
+	RetentionDays       uint64 // stats of older days are removed
+	SaveIntervalSeconds uint64 `toml:",omitempty" json:",omitempty"`
+}
+
+// AuthConfig session token authentication config
+type AuthConfig struct {
+	MaxDelaySeconds       uint64 // accept timestamps in the past within this duration
+	MaxFutureSeconds      uint64 // accept timestamps in the future within this duration
+	RejectLegacySignature bool   // reject signatures without nonce and body hash
 }
 
 // SessionToken session token
@@ -37,6 +119,25 @@ type SessionToken struct {
 	Token string
 	User  string
 	Salt  string `json:"-"`
+
+	Roles   []string `toml:",omitempty" json:",omitempty"` // default is signer
+	Methods []string `toml:",omitempty" json:",omitempty"` // extra allowed rpc methods (eg. `GetStatInfo`)
+}
+
+// session token roles, each role is allowed to call the methods of the lower roles
+const (
+	RoleReadOnly = "read-only"
+	RoleVerifier = "verifier"
+	RoleSigner   = "signer"
+	RoleAdmin    = "admin"
+)
+
+// GetRoles get roles of session token
+func (t *SessionToken) GetRoles() []string {
+	if len(t.Roles) == 0 {
+		return []string{RoleSigner}
+	}
+	return t.Roles
 }
 
 func (t *SessionToken) String() string {
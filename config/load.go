@@ -39,5 +39,11 @@ func LoadConfig(configFile string, check bool) *ServerConfig {
 		}
 	}
 
+	configLoaded = true
 	return serverConfig
 }
+
+// IsLoaded the config is loaded (and checked if required)
+func IsLoaded() bool {
+	return configLoaded
+}
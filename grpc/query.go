@@ -5,12 +5,17 @@ import (
 	"encoding/hex"
 
 	cosmosClient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
 	"github.com/pkg/errors"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/encoding/proto"
 )
@@ -112,3 +117,50 @@ func SimulateTx(
 	}
 	return simRes, nil
 }
+
+// GetMinGasPrices returns the minimum gas prices config of the node
+func GetMinGasPrices(ctx context.Context, clientCtx cosmosClient.Context) (string, error) {
+	nodeClient := node.NewServiceClient(clientCtx)
+	res, err := nodeClient.Config(ctx, &node.ConfigRequest{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return res.MinimumGasPrice, nil
+}
+
+// GetChannelClientLatestHeight get latest height of the counterparty client of the channel
+func GetChannelClientLatestHeight(ctx context.Context, clientCtx cosmosClient.Context, portID, channelID string) (clienttypes.Height, error) {
+	channelClient := channeltypes.NewQueryClient(clientCtx)
+	res, err := channelClient.ChannelClientState(ctx, &channeltypes.QueryChannelClientStateRequest{
+		PortId:    portID,
+		ChannelId: channelID,
+	})
+	if err != nil {
+		return clienttypes.ZeroHeight(), errors.WithStack(err)
+	}
+	if res.IdentifiedClientState == nil {
+		return clienttypes.ZeroHeight(), errors.New("channel client state not found")
+	}
+	var clientState ibcexported.ClientState
+	if err = clientCtx.InterfaceRegistry.UnpackAny(res.IdentifiedClientState.ClientState, &clientState); err != nil {
+		return clienttypes.ZeroHeight(), errors.WithStack(err)
+	}
+	latestHeight := clientState.GetLatestHeight()
+	return clienttypes.NewHeight(latestHeight.GetRevisionNumber(), latestHeight.GetRevisionHeight()), nil
+}
+
+// GetBlockResults get block and tx results of block at height
+func GetBlockResults(ctx context.Context, clientCtx cosmosClient.Context, height int64) (*ctypes.ResultBlock, *ctypes.ResultBlockResults, error) {
+	block, err := clientCtx.Client.Block(ctx, &height)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	results, err := clientCtx.Client.BlockResults(ctx, &height)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if len(block.Block.Data.Txs) != len(results.TxsResults) {
+		return nil, nil, errors.New("block txs and results mismatch")
+	}
+	return block, results, nil
+}
@@ -0,0 +1,138 @@
+// Package metrics provides prometheus metrics of the chain support server.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "injective_chain_support"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "Number of rpc requests by method and error code (0 is success).",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Duration of rpc requests by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	gatewayDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gateway_query_duration_seconds",
+		Help:      "Latency of successful queries to gateway nodes.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"gateway", "kind"})
+
+	gatewayFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_query_failures_total",
+		Help:      "Number of failed queries to gateway nodes.",
+	}, []string{"gateway", "kind"})
+
+	latestHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "latest_block_height",
+		Help:      "Latest observed block height.",
+	})
+
+	mpcSignDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mpc_sign_duration_seconds",
+		Help:      "Duration of mpc signing by result.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300},
+	}, []string{"result"})
+
+	broadcasts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "broadcast_total",
+		Help:      "Number of sent txs by outcome (accepted, or the suggested action of failure).",
+	}, []string{"outcome"})
+
+	mpcBalances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mpc_balance",
+		Help:      "Balance (in the smallest unit) of mpc accounts by denom.",
+	}, []string{"address", "denom"})
+
+	maxHeight     uint64
+	maxHeightLock sync.Mutex
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests,
+		rpcDuration,
+		gatewayDuration,
+		gatewayFailures,
+		latestHeight,
+		mpcSignDuration,
+		broadcasts,
+		mpcBalances,
+	)
+}
+
+// Handler http handler of metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRPCRequest observe rpc request, code is 0 if success
+func ObserveRPCRequest(method string, code int, duration time.Duration) {
+	rpcRequests.WithLabelValues(method, strconv.Itoa(code)).Inc()
+	rpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveGatewayQuery observe query to gateway node,
+// gateway is the label of node which must not contain credentials of the url
+func ObserveGatewayQuery(gateway, kind string, err error, latency time.Duration) {
+	if err != nil {
+		gatewayFailures.WithLabelValues(gateway, kind).Inc()
+		return
+	}
+	gatewayDuration.WithLabelValues(gateway, kind).Observe(latency.Seconds())
+}
+
+// ObserveLatestHeight observe latest block height, lower heights are ignored
+func ObserveLatestHeight(height uint64) {
+	maxHeightLock.Lock()
+	defer maxHeightLock.Unlock()
+	if height > maxHeight {
+		maxHeight = height
+		latestHeight.Set(float64(height))
+	}
+}
+
+// ObserveMPCSign observe mpc signing
+func ObserveMPCSign(duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	mpcSignDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// ObserveBroadcast observe outcome of sending tx
+func ObserveBroadcast(outcome string) {
+	broadcasts.WithLabelValues(outcome).Inc()
+}
+
+// SetMPCBalance set balance of mpc account
+func SetMPCBalance(address, denom string, balance float64) {
+	mpcBalances.WithLabelValues(address, denom).Set(balance)
+}
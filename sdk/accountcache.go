@@ -0,0 +1,117 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+)
+
+var (
+	// DefaultAccountNumberCacheTTL account number is rarely changed
+	DefaultAccountNumberCacheTTL = 24 * time.Hour
+	// DefaultSequenceCacheTTL sequence changes after each tx of the account
+	DefaultSequenceCacheTTL = 3 * time.Second
+)
+
+// AccountCache concurrency safe cache of account number and sequence with ttl
+type AccountCache struct {
+	accountNumberTTL time.Duration
+	sequenceTTL      time.Duration
+
+	lock    sync.RWMutex
+	entries map[string]*AccountCacheEntry
+}
+
+// AccountCacheEntry cached account state
+type AccountCacheEntry struct {
+	Address           string    `json:"address"`
+	AccountNumber     uint64    `json:"accountNumber"`
+	AccountNumberTime time.Time `json:"accountNumberTime"`
+	Sequence          uint64    `json:"sequence"`
+	SequenceTime      time.Time `json:"sequenceTime"`
+}
+
+// NewAccountCache new account cache
+func NewAccountCache(accountNumberTTL, sequenceTTL time.Duration) *AccountCache {
+	return &AccountCache{
+		accountNumberTTL: accountNumberTTL,
+		sequenceTTL:      sequenceTTL,
+		entries:          make(map[string]*AccountCacheEntry),
+	}
+}
+
+// GetAccountNumber get unexpired account number
+func (c *AccountCache) GetAccountNumber(address string) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, exist := c.entries[address]
+	if !exist || entry.AccountNumberTime.IsZero() || time.Since(entry.AccountNumberTime) > c.accountNumberTTL {
+		return 0, false
+	}
+	return entry.AccountNumber, true
+}
+
+// GetSequence get unexpired sequence
+func (c *AccountCache) GetSequence(address string) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, exist := c.entries[address]
+	if !exist || entry.SequenceTime.IsZero() || time.Since(entry.SequenceTime) > c.sequenceTTL {
+		return 0, false
+	}
+	return entry.Sequence, true
+}
+
+// Set set account number and sequence queried at the same time
+func (c *AccountCache) Set(address string, accountNumber, sequence uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	entry := c.getOrCreateEntry(address)
+	entry.AccountNumber = accountNumber
+	entry.AccountNumberTime = now
+	entry.Sequence = sequence
+	entry.SequenceTime = now
+}
+
+// InvalidateSequence invalidate cached sequence (eg. sequence mismatch when broadcasting)
+func (c *AccountCache) InvalidateSequence(address string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if entry, exist := c.entries[address]; exist {
+		entry.SequenceTime = time.Time{}
+		log.Info("invalidate cached sequence", "address", address, "sequence", entry.Sequence)
+	}
+}
+
+// Invalidate remove cached account state
+func (c *AccountCache) Invalidate(address string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, address)
+}
+
+// Entries get copy of cached entries, get all entries if address is empty
+func (c *AccountCache) Entries(address string) []*AccountCacheEntry {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	result := make([]*AccountCacheEntry, 0, len(c.entries))
+	for addr, entry := range c.entries {
+		if address != "" && addr != address {
+			continue
+		}
+		entryCopy := *entry
+		result = append(result, &entryCopy)
+	}
+	return result
+}
+
+func (c *AccountCache) getOrCreateEntry(address string) *AccountCacheEntry {
+	entry, exist := c.entries[address]
+	if !exist {
+		entry = &AccountCacheEntry{Address: address}
+		c.entries[address] = entry
+	}
+	return entry
+}
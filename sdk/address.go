@@ -6,8 +6,6 @@ import (
 
 	"github.com/anyswap/CrossChain-Router/v3/log"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
-	"github.com/btcsuite/btcd/btcec"
-	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/bech32"
@@ -18,13 +16,27 @@ func (b *Bridge) IsValidAddress(address string) bool {
 	return IsValidAddress(b.Prefix, address)
 }
 
+// IsValidReceiver check receiver of token,
+// receivers of the token's ibc routes are also valid
+func (b *Bridge) IsValidReceiver(tokenAddr, address string) bool {
+	if GetAddressPrefix(address) != b.Prefix {
+		return IsValidIBCReceiver(tokenAddr, address)
+	}
+	return IsValidAddress(b.Prefix, address)
+}
+
 // PublicKeyToAddress public key hex string (may be uncompressed) to address
 func (b *Bridge) PublicKeyToAddress(pubKeyHex string) (string, error) {
-	return PublicKeyToAddress(b.Prefix, pubKeyHex)
+	return PublicKeyToAddressWithKeyType(b.Prefix, b.KeyType, pubKeyHex)
 }
 
 func (b *Bridge) VerifyPubKey(address, pubkey string) error {
-	return VerifyPubKey(address, b.Prefix, pubkey)
+	return VerifyPubKeyWithKeyType(address, b.Prefix, b.KeyType, pubkey)
+}
+
+// PubKeyFromStr get public key of bridge key type from hex string
+func (b *Bridge) PubKeyFromStr(pubKeyHex string) (cryptoTypes.PubKey, error) {
+	return PubKeyFromStrWithKeyType(b.KeyType, pubKeyHex)
 }
 
 func IsValidAddress(prefix, address string) bool {
@@ -44,7 +56,12 @@ func IsValidAddress(prefix, address string) bool {
 }
 
 func PublicKeyToAddress(prefix, pubKeyHex string) (string, error) {
-	if pk, err := PubKeyFromStr(pubKeyHex); err != nil {
+	return PublicKeyToAddressWithKeyType(prefix, DefaultKeyType, pubKeyHex)
+}
+
+// PublicKeyToAddressWithKeyType public key hex string to address of the given key type
+func PublicKeyToAddressWithKeyType(prefix string, keyType KeyType, pubKeyHex string) (string, error) {
+	if pk, err := PubKeyFromStrWithKeyType(keyType, pubKeyHex); err != nil {
 		return "", err
 	} else {
 		if accAddress, err := sdk.AccAddressFromHex(pk.Address().String()); err != nil {
@@ -61,29 +78,32 @@ func PublicKeyToAddress(prefix, pubKeyHex string) (string, error) {
 
 // PubKeyFromStr get public key from hex string
 func PubKeyFromStr(pubKeyHex string) (cryptoTypes.PubKey, error) {
+	return PubKeyFromStrWithKeyType(DefaultKeyType, pubKeyHex)
+}
+
+// PubKeyFromStrWithKeyType get public key of the given key type from hex string
+func PubKeyFromStrWithKeyType(keyType KeyType, pubKeyHex string) (cryptoTypes.PubKey, error) {
 	pubKeyHex = strings.TrimPrefix(pubKeyHex, "0x")
 	if bs, err := hex.DecodeString(pubKeyHex); err != nil {
 		return nil, err
 	} else {
-		return PubKeyFromBytes(bs)
+		return keyType.PubKeyFromBytes(bs)
 	}
 }
 
 // PubKeyFromBytes get public key from bytes
 func PubKeyFromBytes(pubKeyBytes []byte) (cryptoTypes.PubKey, error) {
-	if cmp, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256()); err != nil {
-		return nil, err
-	} else {
-		compressedPublicKey := make([]byte, secp256k1.PubKeySize)
-		copy(compressedPublicKey, cmp.SerializeCompressed())
-
-		return &secp256k1.PubKey{Key: compressedPublicKey}, nil
-	}
+	return DefaultKeyType.PubKeyFromBytes(pubKeyBytes)
 }
 
 func VerifyPubKey(address, prefix, pubkey string) error {
-	if addr, err := PublicKeyToAddress(prefix, pubkey); err != nil {
-		log.Warn("public key to address error", "pubkey", pubkey, "prefix", prefix, "err", err)
+	return VerifyPubKeyWithKeyType(address, prefix, DefaultKeyType, pubkey)
+}
+
+// VerifyPubKeyWithKeyType verify address is derived from pubkey of the given key type
+func VerifyPubKeyWithKeyType(address, prefix string, keyType KeyType, pubkey string) error {
+	if addr, err := PublicKeyToAddressWithKeyType(prefix, keyType, pubkey); err != nil {
+		log.Warn("public key to address error", "pubkey", pubkey, "prefix", prefix, "keyType", keyType, "err", err)
 		return err
 	} else {
 		if address != addr {
@@ -0,0 +1,422 @@
+package sdk
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	tokenfactoryTypes "github.com/InjectiveLabs/sdk-go/chain/tokenfactory/types"
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/router"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// BatchMemoPrefix memo prefix of batch tx, memo format is
+	// `batch:<msgStart>-<msgEnd>@<idHash>,...` (msg index is 0-based and inclusive)
+	BatchMemoPrefix = "batch:"
+
+	maxMemoCharacters = 256
+	batchIDHashLength = 16
+)
+
+var (
+	// MaxBatchSwapCount max count of swaps in one batch tx
+	MaxBatchSwapCount = 10
+
+	errEmptyBatch = errors.New("empty batch swaps")
+)
+
+// BatchSwap messages of one swap in batch tx
+type BatchSwap struct {
+	Identifier string `json:"identifier,omitempty"`
+	IDHash     string `json:"id_hash"`
+	MsgStart   int    `json:"msg_start"`
+	MsgEnd     int    `json:"msg_end"`
+}
+
+// BuildBatchRawTransaction build one tx which contains the messages of several swaps.
+// all swaps share the sequence, gas limit and fee of the first swap's extra.
+//
+//nolint:gocyclo // ok
+func (b *Bridge) BuildBatchRawTransaction(argsList []*tokens.BuildTxArgs) (*BuildRawTx, error) {
+	return b.BuildBatchRawTransactionWithContext(baseCtx, argsList)
+}
+
+// BuildBatchRawTransactionWithContext build batch raw tx with context
+func (b *Bridge) BuildBatchRawTransactionWithContext(ctx context.Context, argsList []*tokens.BuildTxArgs) (rawTx *BuildRawTx, err error) {
+	if len(argsList) == 0 {
+		return nil, errEmptyBatch
+	}
+	if len(argsList) > MaxBatchSwapCount {
+		return nil, fmt.Errorf("too many swaps in batch, have %v, max %v", len(argsList), MaxBatchSwapCount)
+	}
+	first := argsList[0]
+	from := first.From
+
+	var msgs []sdk.Msg
+	batchSwaps := make([]*BatchSwap, 0, len(argsList))
+	balances := make(map[string]*big.Int)
+	var denoms []string // denoms in order of first use
+	identifiers := make(map[string]struct{})
+	for _, args := range argsList {
+		if !common.IsEqualIgnoreCase(args.From, from) {
+			return nil, tokens.ErrSenderMismatch
+		}
+		identifier := args.GetUniqueSwapIdentifier()
+		if _, exist := identifiers[identifier]; exist {
+			return nil, fmt.Errorf("duplicate swap in batch: %v", identifier)
+		}
+		identifiers[identifier] = struct{}{}
+
+		multichainToken, err := b.checkBuildTxArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		if args.Extra == nil {
+			args.Extra = &tokens.AllExtras{}
+		}
+		receiver, amount, err := b.getReceiverAndAmount(args, multichainToken)
+		if err != nil {
+			return nil, err
+		}
+		args.SwapValue = amount // SwapValue
+		if _, exist := balances[multichainToken]; !exist {
+			denoms = append(denoms, multichainToken)
+		}
+		swapMsgs, err := b.buildBatchSwapMsgs(ctx, args, receiver, multichainToken, amount, balances)
+		if err != nil {
+			return nil, err
+		}
+		batchSwaps = append(batchSwaps, &BatchSwap{
+			Identifier: identifier,
+			IDHash:     BatchSwapIDHash(identifier),
+			MsgStart:   len(msgs),
+			MsgEnd:     len(msgs) + len(swapMsgs) - 1,
+		})
+		msgs = append(msgs, swapMsgs...)
+	}
+
+	// burn the excess of tokenfactory denoms as single swap tx does,
+	// the burn messages belong to the last swap to keep the memo covering all messages
+	burnMsgs, err := buildBatchBurnMsgs(from, denoms, balances)
+	if err != nil {
+		return nil, err
+	}
+	if len(burnMsgs) > 0 {
+		msgs = append(msgs, burnMsgs...)
+		batchSwaps[len(batchSwaps)-1].MsgEnd = len(msgs) - 1
+	}
+
+	memo := BuildBatchMemo(batchSwaps)
+	if len(memo) > maxMemoCharacters {
+		return nil, fmt.Errorf("batch memo is too long, length %v", len(memo))
+	}
+
+	needEstimateGas := first.Extra.Gas == nil
+	needCalcFee := first.Extra.Fee == nil
+	if needEstimateGas {
+		gasLimit := b.getDefaultGasLimit() * uint64(len(argsList))
+		first.Extra.Gas = &gasLimit
+	}
+	needReserveSeq := first.Extra.Sequence == nil
+	extra, err := b.initExtra(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rawTx == nil && needReserveSeq {
+			b.releaseSeq(first)
+		}
+	}()
+	mpcPubkey := router.GetMPCPublicKey(from)
+	txBuilder, err := b.newTxBuilder(msgs, memo, mpcPubkey, extra)
+	if err != nil {
+		return nil, err
+	}
+	if needEstimateGas {
+		if err := b.adjustGasLimit(ctx, first, txBuilder); err != nil {
+			return nil, err
+		}
+		if needCalcFee {
+			if err := b.adjustFee(ctx, first, txBuilder); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, args := range argsList[1:] {
+		args.Extra.Sequence = extra.Sequence
+		args.Extra.Gas = extra.Gas
+		args.Extra.Fee = extra.Fee
+	}
+
+	accountNumber, err := b.GetAccountNumWithContext(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("build batch raw tx", "swaps", len(argsList), "msgs", len(msgs),
+		"from", from, "memo", memo,
+		"accountNumber", accountNumber, "sequence", *extra.Sequence,
+		"gasLimit", *extra.Gas, "gasFee", *extra.Fee,
+	)
+	encodedTx, err := b.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, err
+	}
+	return &BuildRawTx{
+		TxBuilder:     txBuilder,
+		EncodedTx:     encodedTx,
+		AccountNumber: accountNumber,
+		Sequence:      *extra.Sequence,
+		BatchSwaps:    batchSwaps,
+	}, nil
+}
+
+// buildBatchSwapMsgs build messages of one swap in batch tx.
+// balances is the remaining balances of the sender, and is updated by the built messages.
+// the sender mints the shortage of tokenfactory denoms it created before sending.
+func (b *Bridge) buildBatchSwapMsgs(
+	ctx context.Context,
+	args *tokens.BuildTxArgs,
+	to, denom string,
+	amount *big.Int,
+	balances map[string]*big.Int,
+) (msgs []sdk.Msg, err error) {
+	from := args.From
+	balance, exist := balances[denom]
+	if !exist {
+		bal, err := b.GetDenomBalance(ctx, from, denom)
+		if err != nil {
+			return nil, err
+		}
+		balance = bal.BigInt()
+		balances[denom] = balance
+	}
+
+	var isCreator bool
+	if IsTokenFactoryDenom(denom) {
+		creator, _, errt := tokenfactoryTypes.DeconstructDenom(denom)
+		if errt != nil {
+			return nil, errt
+		}
+		isCreator = creator == from
+	}
+
+	bridgeFeeReceiver := getBridgeFeeReceiver(args)
+	needed := new(big.Int).Set(amount)
+	if bridgeFeeReceiver != "" {
+		needed.Add(needed, args.Extra.BridgeFee)
+	}
+
+	if balance.Cmp(needed) < 0 {
+		if !isCreator {
+			log.Info("balance not enough", "swapID", args.SwapID, "denom", denom, "balance", balance, "amount", amount, "fee", args.Extra.BridgeFee)
+			return nil, tokens.ErrBalanceNotEnough
+		}
+		mintAmount := new(big.Int).Sub(needed, balance)
+		msgs = append(msgs, BuildMintMsg(from, sdk.NewCoin(denom, sdk.NewIntFromBigInt(mintAmount))))
+		balance.Add(balance, mintAmount)
+	}
+
+	deliverMsg, err := b.buildDeliverMsg(ctx, args, from, to, denom, amount)
+	if err != nil {
+		return nil, err
+	}
+	msgs = append(msgs, deliverMsg)
+	balance.Sub(balance, amount)
+
+	if bridgeFeeReceiver != "" {
+		msgs = append(msgs, BuildSendMsg(from, bridgeFeeReceiver, denom, args.Extra.BridgeFee))
+		balance.Sub(balance, args.Extra.BridgeFee)
+		log.Info("build charge fee on dest chain", "swapID", args.SwapID, "from", from, "receiver", bridgeFeeReceiver, "denom", denom, "fee", args.Extra.BridgeFee)
+	}
+	return msgs, nil
+}
+
+// buildBatchBurnMsgs burn the remaining balances of tokenfactory denoms created by the sender
+func buildBatchBurnMsgs(from string, denoms []string, balances map[string]*big.Int) (msgs []sdk.Msg, err error) {
+	for _, denom := range denoms {
+		balance := balances[denom]
+		if balance.Sign() <= 0 || !IsTokenFactoryDenom(denom) {
+			continue
+		}
+		creator, _, errt := tokenfactoryTypes.DeconstructDenom(denom)
+		if errt != nil {
+			return nil, errt
+		}
+		if creator != from {
+			continue
+		}
+		msgs = append(msgs, BuildBurnMsg(from, sdk.NewCoin(denom, sdk.NewIntFromBigInt(new(big.Int).Set(balance)))))
+		balance.SetInt64(0)
+	}
+	return msgs, nil
+}
+
+// BatchSwapIDHash short hash of swap identifier used in batch memo
+func BatchSwapIDHash(identifier string) string {
+	return hex.EncodeToString(Sha256Sum([]byte(identifier)))[:batchIDHashLength]
+}
+
+// BuildBatchMemo build memo of batch tx
+func BuildBatchMemo(batchSwaps []*BatchSwap) string {
+	entries := make([]string, 0, len(batchSwaps))
+	for _, swap := range batchSwaps {
+		entries = append(entries, fmt.Sprintf("%d-%d@%s", swap.MsgStart, swap.MsgEnd, swap.IDHash))
+	}
+	return BatchMemoPrefix + strings.Join(entries, ",")
+}
+
+// IsBatchMemo is memo of batch tx
+func IsBatchMemo(memo string) bool {
+	return strings.HasPrefix(memo, BatchMemoPrefix)
+}
+
+// ParseBatchMemo parse memo of batch tx, the swaps must cover all the msgCount messages
+func ParseBatchMemo(memo string, msgCount int) ([]*BatchSwap, error) {
+	if !IsBatchMemo(memo) {
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	entries := strings.Split(strings.TrimPrefix(memo, BatchMemoPrefix), ",")
+	batchSwaps := make([]*BatchSwap, 0, len(entries))
+	nextStart := 0
+	for _, entry := range entries {
+		parts := strings.Split(entry, "@")
+		if len(parts) != 2 || len(parts[1]) != batchIDHashLength {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		if _, err := hex.DecodeString(parts[1]); err != nil {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		indexes := strings.Split(parts[0], "-")
+		if len(indexes) != 2 {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		msgStart, err := strconv.Atoi(indexes[0])
+		if err != nil {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		msgEnd, err := strconv.Atoi(indexes[1])
+		if err != nil {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		if msgStart != nextStart || msgEnd < msgStart {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		nextStart = msgEnd + 1
+		batchSwaps = append(batchSwaps, &BatchSwap{
+			IDHash:   parts[1],
+			MsgStart: msgStart,
+			MsgEnd:   msgEnd,
+		})
+	}
+	if nextStart != msgCount {
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	return batchSwaps, nil
+}
+
+// VerifyBatchSwap verify the swap is delivered by the batch tx
+func (b *Bridge) VerifyBatchSwap(txHash string, args *tokens.BuildTxArgs) error {
+	return b.VerifyBatchSwapWithContext(baseCtx, txHash, args)
+}
+
+// VerifyBatchSwapWithContext verify the swap is delivered by the batch tx with context
+func (b *Bridge) VerifyBatchSwapWithContext(ctx context.Context, txHash string, args *tokens.BuildTxArgs) error {
+	txr, err := b.GetTransactionByHash(ctx, txHash)
+	if err != nil {
+		log.Debug(b.ChainConfig.BlockChain+" VerifyBatchSwap get tx failed", "tx", txHash, "err", err)
+		return tokens.ErrTxNotFound
+	}
+	if txr.TxResponse.Code != 0 {
+		return tokens.ErrTxWithWrongStatus
+	}
+	denom := router.GetCachedMultichainToken(args.GetTokenID(), b.ChainConfig.ChainID)
+	if denom == "" {
+		return tokens.ErrMissTokenConfig
+	}
+	return verifyBatchSwapLogs(txHash, txr.Tx.Body.Memo, txr.TxResponse.MessageLogs(), args, denom)
+}
+
+// verifyBatchSwapLogs verify the swap is delivered by the batch tx of memo and message logs
+func verifyBatchSwapLogs(txHash, memo string, messageLogs sdk.ABCIMessageLogs, args *tokens.BuildTxArgs, denom string) error {
+	batchSwaps, err := ParseBatchMemo(memo, len(messageLogs))
+	if err != nil {
+		return err
+	}
+	for _, messageLog := range messageLogs {
+		if signer := getMessageSigner(messageLog); signer != args.From {
+			log.Warn("batch tx signer mismatch", "tx", txHash, "msgIndex", messageLog.MsgIndex, "have", signer, "want", args.From)
+			return tokens.ErrTxWithWrongSender
+		}
+	}
+
+	identifier := args.GetUniqueSwapIdentifier()
+	idHash := BatchSwapIDHash(identifier)
+	var batchSwap *BatchSwap
+	for _, swap := range batchSwaps {
+		if swap.IDHash == idHash {
+			batchSwap = swap
+			break
+		}
+	}
+	if batchSwap == nil {
+		log.Warn("swap not found in batch tx", "tx", txHash, "identifier", identifier)
+		return tokens.ErrSwapoutLogNotFound
+	}
+	received := big.NewInt(0)
+	for i := batchSwap.MsgStart; i <= batchSwap.MsgEnd; i++ {
+		received.Add(received, getTransferAmount(messageLogs[i], args.From, args.Bind, denom))
+		received.Add(received, getIBCTransferAmount(messageLogs[i], args.From, args.Bind, denom))
+	}
+	if received.Sign() == 0 {
+		return tokens.ErrTxWithWrongReceiver
+	}
+	if args.SwapValue != nil && received.Cmp(args.SwapValue) != 0 {
+		log.Warn("batch swap value mismatch", "tx", txHash, "identifier", identifier, "have", received, "want", args.SwapValue)
+		return tokens.ErrTxWithWrongValue
+	}
+	return nil
+}
+
+// getMessageSigner get signer of message in message log,
+// which is the first sender of the message event (emitted before the module events)
+func getMessageSigner(messageLog sdk.ABCIMessageLog) string {
+	for _, event := range messageLog.Events {
+		if event.Type != MessageType {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == "sender" {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
+
+// getTransferAmount get total amount of denom transferred from sender to recipient in message log
+func getTransferAmount(messageLog sdk.ABCIMessageLog, sender, recipient, denom string) *big.Int {
+	total := big.NewInt(0)
+	for _, transfer := range GetTransfers(messageLog) {
+		if transfer.Sender != sender || transfer.Recipient != recipient {
+			continue
+		}
+		coins, err := ParseCoinsNormalized(transfer.Amount)
+		if err != nil {
+			log.Warn("parse coins failed", "amount", transfer.Amount, "err", err)
+			continue
+		}
+		amount := coins.AmountOfNoDenomValidation(denom)
+		if !amount.IsNil() {
+			total.Add(total, amount.BigInt())
+		}
+	}
+	return total
+}
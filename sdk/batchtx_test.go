@@ -0,0 +1,169 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+)
+
+const (
+	testBatchFrom  = "inj1mpc"
+	testBatchDenom = "inj"
+)
+
+func TestBatchMemoRoundTrip(t *testing.T) {
+	batchSwaps := []*BatchSwap{
+		{IDHash: BatchSwapIDHash("1:0x01:1"), MsgStart: 0, MsgEnd: 0},
+		{IDHash: BatchSwapIDHash("1:0x02:1"), MsgStart: 1, MsgEnd: 3},
+		{IDHash: BatchSwapIDHash("1:0x03:2"), MsgStart: 4, MsgEnd: 4},
+	}
+	memo := BuildBatchMemo(batchSwaps)
+	if !IsBatchMemo(memo) {
+		t.Fatalf("built memo is not batch memo: %v", memo)
+	}
+	parsed, err := ParseBatchMemo(memo, 5)
+	if err != nil {
+		t.Fatalf("parse batch memo failed: %v", err)
+	}
+	if len(parsed) != len(batchSwaps) {
+		t.Fatalf("batch swaps count mismatch, have %v want %v", len(parsed), len(batchSwaps))
+	}
+	for i, swap := range parsed {
+		want := batchSwaps[i]
+		if swap.IDHash != want.IDHash || swap.MsgStart != want.MsgStart || swap.MsgEnd != want.MsgEnd {
+			t.Errorf("batch swap %v mismatch, have %+v want %+v", i, swap, want)
+		}
+	}
+}
+
+func TestParseBatchMemo(t *testing.T) {
+	idHash1 := BatchSwapIDHash("1:0x01:1")
+	idHash2 := BatchSwapIDHash("1:0x02:1")
+	tests := []struct {
+		name     string
+		memo     string
+		msgCount int
+		valid    bool
+	}{
+		{"one swap", BatchMemoPrefix + "0-1@" + idHash1, 2, true},
+		{"two swaps", BatchMemoPrefix + "0-0@" + idHash1 + ",1-2@" + idHash2, 3, true},
+		{"not batch memo", "0-1@" + idHash1, 2, false},
+		{"empty", BatchMemoPrefix, 0, false},
+		{"gap", BatchMemoPrefix + "0-0@" + idHash1 + ",2-2@" + idHash2, 3, false},
+		{"overlap", BatchMemoPrefix + "0-1@" + idHash1 + ",1-2@" + idHash2, 3, false},
+		{"not start at 0", BatchMemoPrefix + "1-2@" + idHash1, 3, false},
+		{"end before start", BatchMemoPrefix + "0-0@" + idHash1 + ",2-1@" + idHash2, 3, false},
+		{"less than msg count", BatchMemoPrefix + "0-0@" + idHash1 + ",1-1@" + idHash2, 3, false},
+		{"more than msg count", BatchMemoPrefix + "0-0@" + idHash1 + ",1-2@" + idHash2, 2, false},
+		{"negative index", BatchMemoPrefix + "-1-1@" + idHash1, 2, false},
+		{"not number index", BatchMemoPrefix + "0-a@" + idHash1, 2, false},
+		{"missing index", BatchMemoPrefix + "0@" + idHash1, 1, false},
+		{"missing idhash", BatchMemoPrefix + "0-1", 2, false},
+		{"short idhash", BatchMemoPrefix + "0-1@" + idHash1[1:], 2, false},
+		{"long idhash", BatchMemoPrefix + "0-1@" + idHash1 + "0", 2, false},
+		{"not hex idhash", BatchMemoPrefix + "0-1@" + strings.Repeat("x", batchIDHashLength), 2, false},
+		{"two @", BatchMemoPrefix + "0-1@" + idHash1 + "@" + idHash2, 2, false},
+	}
+	for _, test := range tests {
+		batchSwaps, err := ParseBatchMemo(test.memo, test.msgCount)
+		if test.valid && err != nil {
+			t.Errorf("%v: parse batch memo failed: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: parse wrong batch memo success: %v", test.name, batchSwaps)
+		}
+	}
+}
+
+func newTestMessageLog(signer string, events ...sdk.StringEvent) sdk.ABCIMessageLog {
+	messageEvent := sdk.StringEvent{Type: MessageType, Attributes: []sdk.Attribute{{Key: "sender", Value: signer}}}
+	return sdk.ABCIMessageLog{Events: append(sdk.StringEvents{messageEvent}, events...)}
+}
+
+func newTestTransferEvent(sender, recipient, amount string) sdk.StringEvent {
+	return sdk.StringEvent{Type: TransferType, Attributes: []sdk.Attribute{
+		{Key: "recipient", Value: recipient},
+		{Key: "sender", Value: sender},
+		{Key: "amount", Value: amount},
+	}}
+}
+
+func newTestSendPacketEvent(t *testing.T, sender, receiver, denom, amount string) sdk.StringEvent {
+	t.Helper()
+	data, err := json.Marshal(transfertypes.NewFungibleTokenPacketData(denom, amount, sender, receiver))
+	if err != nil {
+		t.Fatalf("marshal packet data failed: %v", err)
+	}
+	return sdk.StringEvent{Type: channeltypes.EventTypeSendPacket, Attributes: []sdk.Attribute{
+		{Key: channeltypes.AttributeKeyData, Value: string(data)},
+	}}
+}
+
+func newTestBatchSwapArgs(swapID, bind string) *tokens.BuildTxArgs {
+	args := &tokens.BuildTxArgs{}
+	args.SwapID = swapID
+	args.LogIndex = 1
+	args.FromChainID = big.NewInt(1)
+	args.Bind = bind
+	args.From = testBatchFrom
+	return args
+}
+
+func TestVerifyBatchSwapLogs(t *testing.T) {
+	args1 := newTestBatchSwapArgs("0x01", "inj1bind1")
+	args2 := newTestBatchSwapArgs("0x02", "osmo1bind2")
+	args3 := newTestBatchSwapArgs("0x03", "inj1bind3")
+	memo := BuildBatchMemo([]*BatchSwap{
+		{IDHash: BatchSwapIDHash(args1.GetUniqueSwapIdentifier()), MsgStart: 0, MsgEnd: 1},
+		{IDHash: BatchSwapIDHash(args2.GetUniqueSwapIdentifier()), MsgStart: 2, MsgEnd: 2},
+	})
+	messageLogs := sdk.ABCIMessageLogs{
+		// bank transfer of swap 1 in two messages (eg. minted shortage)
+		newTestMessageLog(testBatchFrom, newTestTransferEvent(testBatchFrom, "inj1bind1", "60inj")),
+		newTestMessageLog(testBatchFrom,
+			newTestTransferEvent(testBatchFrom, "inj1bind1", "40inj,5usdt"),
+			newTestTransferEvent("inj1other", "inj1bind1", "1000inj")),
+		// ibc transfer of swap 2
+		newTestMessageLog(testBatchFrom,
+			newTestTransferEvent(testBatchFrom, "inj1escrow", "200inj"),
+			newTestSendPacketEvent(t, testBatchFrom, "osmo1bind2", testBatchDenom, "200")),
+	}
+
+	tests := []struct {
+		name        string
+		args        *tokens.BuildTxArgs
+		swapValue   int64
+		messageLogs sdk.ABCIMessageLogs
+		err         error
+	}{
+		{"bank transfers", args1, 100, messageLogs, nil},
+		{"ibc transfer", args2, 200, messageLogs, nil},
+		{"no swap value", args1, 0, messageLogs, nil},
+		{"value mismatch", args1, 1100, messageLogs, tokens.ErrTxWithWrongValue},
+		{"swap not in batch", args3, 100, messageLogs, tokens.ErrSwapoutLogNotFound},
+		{"memo not cover all messages", args1, 100, append(messageLogs, newTestMessageLog(testBatchFrom)), tokens.ErrTxWithWrongMemo},
+		{"wrong signer", args1, 100, sdk.ABCIMessageLogs{
+			messageLogs[0], messageLogs[1], newTestMessageLog("inj1other", messageLogs[2].Events[1:]...),
+		}, tokens.ErrTxWithWrongSender},
+		{"no transfer to receiver", args2, 200, sdk.ABCIMessageLogs{
+			messageLogs[0], messageLogs[1], newTestMessageLog(testBatchFrom, newTestTransferEvent(testBatchFrom, "inj1bind1", "200inj")),
+		}, tokens.ErrTxWithWrongReceiver},
+	}
+	for _, test := range tests {
+		args := *test.args
+		if test.swapValue > 0 {
+			args.SwapValue = big.NewInt(test.swapValue)
+		}
+		err := verifyBatchSwapLogs("txhash", memo, test.messageLogs, &args, testBatchDenom)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%v: verify batch swap error mismatch, have %v want %v", test.name, err, test.err)
+		}
+	}
+}
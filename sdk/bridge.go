@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/anyswap/CrossChain-Router/v3/tokens/base"
 	cosmosClient "github.com/cosmos/cosmos-sdk/client"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
 )
 
 var (
@@ -29,11 +31,18 @@ type Bridge struct {
 
 	ClientContext cosmosClient.Context
 
-	Prefix string
-	Denom  string
+	Prefix  string
+	Denom   string
+	KeyType KeyType
 
 	//cache GetChainId rpc call result
 	ChainName string
+
+	// AccountCache cache of account number and sequence
+	AccountCache *AccountCache
+
+	// SequenceManager local sequence allocator of senders
+	SequenceManager *SequenceManager
 }
 
 // NewCrossChainBridge new bridge
@@ -43,6 +52,9 @@ func NewCrossChainBridge() *Bridge {
 		NonceSetterBase: base.NewNonceSetterBase(),
 		TxConfig:        clientCtx.TxConfig,
 		ClientContext:   clientCtx,
+		KeyType:         DefaultKeyType,
+		AccountCache:    NewAccountCache(DefaultAccountNumberCacheTTL, DefaultSequenceCacheTTL),
+		SequenceManager: NewSequenceManager(),
 	}
 }
 
@@ -56,6 +68,27 @@ func (b *Bridge) SetPrefixAndDenom(prefix, denom string) {
 	config.Seal()
 }
 
+// SetKeyType set account key type
+func (b *Bridge) SetKeyType(keyType KeyType) {
+	b.KeyType = keyType
+	log.Info("SetKeyType finished", "keyType", keyType)
+}
+
+// ParseChainConfigExtra parse chain config extra of format `prefix:denom[:keyType]`
+func ParseChainConfigExtra(extra string) (prefix, denom string, keyType KeyType, err error) {
+	fields := strings.Split(extra, ":")
+	if len(fields) != 2 && len(fields) != 3 {
+		return "", "", "", fmt.Errorf("chainConfig extra error")
+	}
+	prefix, denom = fields[0], fields[1]
+	if len(fields) == 3 {
+		keyType, err = GetKeyType(fields[2])
+	} else {
+		keyType = DefaultKeyType
+	}
+	return prefix, denom, keyType, err
+}
+
 // InitAfterConfig init variables (ie. extra members) after loading config
 func (b *Bridge) InitAfterConfig() {
 }
@@ -64,6 +97,7 @@ func (b *Bridge) InitAfterConfig() {
 func (b *Bridge) SetGatewayConfig(gatewayCfg *tokens.GatewayConfig) {
 	b.CrossChainBridgeBase.SetGatewayConfig(gatewayCfg)
 	b.initGrpcClients()
+	b.initGatewayHealth()
 }
 
 // InitRouterInfo init router info
@@ -71,15 +105,19 @@ func (b *Bridge) InitRouterInfo(routerContract, routerVersion string) (err error
 	if routerContract == "" {
 		return nil
 	}
+	defer func() {
+		setRouterInfoStatus(routerContract, err)
+	}()
 
 	chainID := b.ChainConfig.ChainID
 	log.Info(fmt.Sprintf("[%5v] start init router info", chainID), "routerContract", routerContract)
 
-	extra := strings.Split(b.ChainConfig.Extra, ":")
-	if len(extra) != 2 {
-		return fmt.Errorf("chainConfig extra error")
+	prefix, denom, keyType, err := ParseChainConfigExtra(b.ChainConfig.Extra)
+	if err != nil {
+		return err
 	} else if b.Prefix == "" {
-		b.SetPrefixAndDenom(extra[0], extra[1])
+		b.SetPrefixAndDenom(prefix, denom)
+		b.SetKeyType(keyType)
 		err := sdk.ValidateDenom(b.Denom)
 		if err != nil {
 			log.Warn("wrong denom: %v %w", b.Denom, err)
@@ -129,7 +167,14 @@ func (b *Bridge) SetTokenConfig(tokenAddr string, tokenCfg *tokens.TokenConfig)
 	isReload := router.IsReloading
 	logErrFunc := log.GetLogFuncOr(isReload, log.Errorf, log.Fatalf)
 
-	if strings.Contains(tokenCfg.ContractAddress, "/") {
+	if strings.HasPrefix(tokenCfg.ContractAddress, transfertypes.DenomPrefix+"/") {
+		if err := transfertypes.ValidateIBCDenom(tokenCfg.ContractAddress); err != nil {
+			logErrFunc("wrong ibc denom %v: %v", tokenCfg.ContractAddress, err)
+			if isReload {
+				return
+			}
+		}
+	} else if IsTokenFactoryDenom(tokenCfg.ContractAddress) {
 		creator, subdenom, err := tokenfactoryTypes.DeconstructDenom(tokenCfg.ContractAddress)
 		if err != nil {
 			logErrFunc("deconstruct denom %v failed: %v", tokenCfg.ContractAddress, err)
@@ -153,17 +198,35 @@ func (b *Bridge) SetTokenConfig(tokenAddr string, tokenCfg *tokens.TokenConfig)
 			}
 		}
 	}
+
+	cfgExtra, err := ParseTokenConfigExtra(tokenCfg.Extra)
+	if err != nil {
+		logErrFunc("wrong token config extra %v: %v", tokenCfg.Extra, err)
+		if isReload {
+			return
+		}
+	} else {
+		setIBCRoutes(tokenAddr, cfgExtra.IBCRoutes)
+		for _, route := range cfgExtra.IBCRoutes {
+			log.Info("set ibc route success", "token", tokenAddr, "prefix", route.Prefix, "port", route.SourcePort, "channel", route.SourceChannel)
+		}
+	}
 }
 
 // GetTransaction impl
 func (b *Bridge) GetTransaction(txHash string) (tx interface{}, err error) {
-	return b.GetTransactionByHash(txHash)
+	return b.GetTransactionByHash(baseCtx, txHash)
 }
 
 // GetTransactionStatus impl
 func (b *Bridge) GetTransactionStatus(txHash string) (status *tokens.TxStatus, err error) {
+	return b.GetTransactionStatusWithContext(baseCtx, txHash)
+}
+
+// GetTransactionStatusWithContext get tx status with context
+func (b *Bridge) GetTransactionStatusWithContext(ctx context.Context, txHash string) (status *tokens.TxStatus, err error) {
 	status = new(tokens.TxStatus)
-	if res, err := b.GetTransactionByHash(txHash); err != nil {
+	if res, err := b.GetTransactionByHash(ctx, txHash); err != nil {
 		log.Trace(b.ChainConfig.BlockChain+" GetTransactionStatus fail", "tx", txHash, "err", err)
 		return status, err
 	} else {
@@ -175,7 +238,7 @@ func (b *Bridge) GetTransactionStatus(txHash string) (status *tokens.TxStatus, e
 		} else {
 			status.BlockHeight = txHeight
 		}
-		if blockNumber, err := b.GetLatestBlockNumber(); err == nil {
+		if blockNumber, err := b.GetLatestBlockNumberWithContext(ctx); err == nil {
 			if blockNumber > status.BlockHeight {
 				status.Confirmations = blockNumber - status.BlockHeight
 			}
@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	// BroadcastTimeout default timeout of broadcasting tx to each endpoint
+	BroadcastTimeout = 30 * time.Second
+
+	maxBroadcastReports = 1000
+
+	broadcastReports      = make(map[string]*BroadcastReport)
+	broadcastReportHashes []string
+	broadcastReportsLock  sync.RWMutex
+)
+
+// BroadcastResult broadcast result of one endpoint
+type BroadcastResult struct {
+	Endpoint   string      `json:"endpoint"`
+	TxResponse *TxResponse `json:"txResponse,omitempty"`
+	Error      string      `json:"error,omitempty"`
+
+	Err error `json:"-"`
+}
+
+// BroadcastReport broadcast results of all endpoints
+type BroadcastReport struct {
+	TxHash   string             `json:"txhash"`
+	Accepted []string           `json:"accepted"`
+	Results  []*BroadcastResult `json:"results"`
+}
+
+// IsAccepted the tx is accepted by the endpoint (added to mempool)
+func (r *BroadcastResult) IsAccepted() bool {
+	if r.Err != nil || r.TxResponse == nil {
+		return false
+	}
+	res := r.TxResponse
+	if res.Code == 0 {
+		return true
+	}
+	return res.Code == sdkerrors.ErrTxInMempoolCache.ABCICode() &&
+		(res.Codespace == sdkerrors.RootCodespace || res.Codespace == "")
+}
+
+// BroadcastTx broadcast tx to all grpc and rest endpoints concurrently,
+// returns the first accepted response, or any rejected response if none accepted.
+// the broadcasts to the remaining endpoints are canceled if ctx is done.
+func (b *Bridge) BroadcastTx(ctx context.Context, req *BroadcastTxRequest) (string, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(req.TxBytes)
+	if err != nil {
+		return "", err
+	}
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	grpcURLs, restURLs := grpcEndpoints(), b.restEndpoints()
+	endpoints := len(grpcURLs) + len(restURLs)
+	if endpoints == 0 {
+		return "", wrapRPCQueryError(fmt.Errorf("no broadcast endpoints"), "BroadcastTx")
+	}
+	resultsCh := make(chan *BroadcastResult, endpoints)
+	for _, url := range grpcURLs {
+		go func(url string) {
+			start := time.Now()
+			res, err := b.GRPCBroadcastTxOf(ctx, url, txBytes)
+			recordGatewayQuery(url, err, time.Since(start))
+			resultsCh <- newBroadcastResult(url, res, err)
+		}(url)
+	}
+	for _, url := range restURLs {
+		go func(url string) {
+			start := time.Now()
+			res, err := b.restBroadcastTxOf(ctx, url, reqData)
+			recordGatewayQuery(url, err, time.Since(start))
+			resultsCh <- newBroadcastResult(url, res, err)
+		}(url)
+	}
+
+	txHash := fmt.Sprintf("%X", Sha256Sum(txBytes))
+	report := &BroadcastReport{TxHash: txHash}
+	var rejected *BroadcastResult
+	for i := 0; i < endpoints; i++ {
+		result := <-resultsCh
+		report.addResult(result)
+		if result.IsAccepted() {
+			// collect the remaining results in background
+			go collectBroadcastResults(report, resultsCh, endpoints-i-1)
+			return marshalBroadcastTxResponse(result.TxResponse)
+		}
+		if rejected == nil && result.Err == nil && result.TxResponse != nil {
+			rejected = result
+		}
+	}
+	report.finish()
+	if rejected != nil {
+		return marshalBroadcastTxResponse(rejected.TxResponse)
+	}
+	return "", wrapRPCQueryError(report.Results[len(report.Results)-1].Err, "BroadcastTx")
+}
+
+func (b *Bridge) restBroadcastTxOf(ctx context.Context, url string, reqData []byte) (*TxResponse, error) {
+	restApi := joinURLPath(url, BroadTx)
+	res, err := restPost(ctx, "BroadcastTx", restApi, reqData)
+	if err != nil {
+		return nil, wrapRPCQueryError(err, "BroadcastTx")
+	}
+	var txResponse *BroadcastTxResponse
+	if err := json.Unmarshal([]byte(res), &txResponse); err != nil {
+		return nil, wrapRPCQueryError(err, "BroadcastTx")
+	}
+	if txResponse == nil || txResponse.TxResponse == nil {
+		return nil, wrapRPCQueryError(fmt.Errorf("empty tx response: %v", res), "BroadcastTx")
+	}
+	return txResponse.TxResponse, nil
+}
+
+func newBroadcastResult(endpoint string, res *TxResponse, err error) *BroadcastResult {
+	result := &BroadcastResult{
+		Endpoint:   endpoint,
+		TxResponse: res,
+		Err:        err,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func marshalBroadcastTxResponse(res *TxResponse) (string, error) {
+	data, err := json.Marshal(BroadcastTxResponse{TxResponse: res})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func collectBroadcastResults(report *BroadcastReport, resultsCh <-chan *BroadcastResult, remain int) {
+	for i := 0; i < remain; i++ {
+		report.addResult(<-resultsCh)
+	}
+	report.finish()
+}
+
+func (r *BroadcastReport) addResult(result *BroadcastResult) {
+	r.Results = append(r.Results, result)
+	if result.IsAccepted() {
+		r.Accepted = append(r.Accepted, result.Endpoint)
+	}
+}
+
+// finish log the disagreeing responses and save the report
+func (r *BroadcastReport) finish() {
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			log.Warn("broadcast tx to endpoint failed", "txhash", r.TxHash, "endpoint", result.Endpoint, "err", result.Err)
+		case !result.IsAccepted() && len(r.Accepted) > 0:
+			log.Warn("broadcast tx responses disagree", "txhash", r.TxHash, "endpoint", result.Endpoint,
+				"codespace", result.TxResponse.Codespace, "code", result.TxResponse.Code, "rawLog", result.TxResponse.RawLog)
+		case result.TxResponse.TxHash != r.TxHash:
+			log.Warn("broadcast tx response hash mismatch", "txhash", r.TxHash, "endpoint", result.Endpoint, "have", result.TxResponse.TxHash)
+		}
+	}
+	log.Info("broadcast tx finished", "txhash", r.TxHash, "accepted", r.Accepted, "endpoints", len(r.Results))
+
+	broadcastReportsLock.Lock()
+	defer broadcastReportsLock.Unlock()
+	if _, exist := broadcastReports[r.TxHash]; !exist {
+		broadcastReportHashes = append(broadcastReportHashes, r.TxHash)
+	}
+	broadcastReports[r.TxHash] = r
+	for len(broadcastReportHashes) > maxBroadcastReports {
+		delete(broadcastReports, broadcastReportHashes[0])
+		broadcastReportHashes = broadcastReportHashes[1:]
+	}
+}
+
+// GetBroadcastReport get broadcast results of all endpoints of tx
+func GetBroadcastReport(txHash string) *BroadcastReport {
+	broadcastReportsLock.RLock()
+	defer broadcastReportsLock.RUnlock()
+	return broadcastReports[txHash]
+}
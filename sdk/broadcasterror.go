@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// BroadcastAction suggested action of failed broadcast
+type BroadcastAction string
+
+// broadcast actions
+const (
+	BroadcastActionNone    BroadcastAction = "none"     // tx is accepted
+	BroadcastActionRetry   BroadcastAction = "retry"    // rebuild or resend later
+	BroadcastActionBumpFee BroadcastAction = "bump_fee" // rebuild with more gas or fee
+	BroadcastActionGiveUp  BroadcastAction = "give_up"  // retrying will not succeed
+)
+
+// broadcast errors (all of them are `tokens.ErrBroadcastTx`)
+var (
+	ErrBroadcastInsufficientFee = errors.New("insufficient fee")
+	ErrBroadcastOutOfGas        = errors.New("out of gas")
+	ErrBroadcastWrongSequence   = errors.New("wrong sequence")
+	ErrBroadcastTxInMempool     = errors.New("tx already in mempool")
+	ErrBroadcastMempoolIsFull   = errors.New("mempool is full")
+	ErrBroadcastUnauthorized    = errors.New("unauthorized")
+	ErrBroadcastTxRejected      = errors.New("tx rejected")
+)
+
+// BroadcastError error of broadcast tx response with non zero code
+type BroadcastError struct {
+	Err       error
+	Action    BroadcastAction
+	Codespace string
+	Code      uint32
+	RawLog    string
+	TxHash    string
+}
+
+// NewBroadcastError classify broadcast tx response by codespace and code
+func NewBroadcastError(txResponse *TxResponse) *BroadcastError {
+	err, action := classifyBroadcastCode(txResponse.Codespace, txResponse.Code)
+	return &BroadcastError{
+		Err:       err,
+		Action:    action,
+		Codespace: txResponse.Codespace,
+		Code:      txResponse.Code,
+		RawLog:    txResponse.RawLog,
+		TxHash:    txResponse.TxHash,
+	}
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("%v: %v (codespace: %v, code: %v, action: %v), %v",
+		tokens.ErrBroadcastTx, e.Err, e.Codespace, e.Code, e.Action, e.RawLog)
+}
+
+// Unwrap returns the classified error
+func (e *BroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// Is broadcast error is also `tokens.ErrBroadcastTx`
+func (e *BroadcastError) Is(target error) bool {
+	return target == tokens.ErrBroadcastTx
+}
+
+// GetBroadcastAction get suggested action of broadcast error
+func GetBroadcastAction(err error) BroadcastAction {
+	if err == nil {
+		return BroadcastActionNone
+	}
+	var broadcastErr *BroadcastError
+	if errors.As(err, &broadcastErr) {
+		return broadcastErr.Action
+	}
+	return BroadcastActionRetry
+}
+
+func classifyBroadcastCode(codespace string, code uint32) (error, BroadcastAction) {
+	// codespace is empty in responses of some old nodes
+	if codespace != sdkerrors.RootCodespace && codespace != "" {
+		return ErrBroadcastTxRejected, BroadcastActionGiveUp
+	}
+	switch code {
+	case sdkerrors.ErrInsufficientFee.ABCICode():
+		return ErrBroadcastInsufficientFee, BroadcastActionBumpFee
+	case sdkerrors.ErrOutOfGas.ABCICode():
+		return ErrBroadcastOutOfGas, BroadcastActionBumpFee
+	case sdkerrors.ErrWrongSequence.ABCICode():
+		return ErrBroadcastWrongSequence, BroadcastActionRetry
+	case sdkerrors.ErrTxInMempoolCache.ABCICode():
+		return ErrBroadcastTxInMempool, BroadcastActionNone
+	case sdkerrors.ErrMempoolIsFull.ABCICode():
+		return ErrBroadcastMempoolIsFull, BroadcastActionRetry
+	case sdkerrors.ErrUnauthorized.ABCICode():
+		return ErrBroadcastUnauthorized, BroadcastActionGiveUp
+	default:
+		return ErrBroadcastTxRejected, BroadcastActionGiveUp
+	}
+}
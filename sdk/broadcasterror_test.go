@@ -0,0 +1,48 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+)
+
+func TestClassifyBroadcastCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		codespace string
+		code      uint32
+		err       error
+		action    BroadcastAction
+	}{
+		{"insufficient fee", "sdk", 13, ErrBroadcastInsufficientFee, BroadcastActionBumpFee},
+		{"out of gas", "sdk", 11, ErrBroadcastOutOfGas, BroadcastActionBumpFee},
+		{"wrong sequence", "sdk", 32, ErrBroadcastWrongSequence, BroadcastActionRetry},
+		{"tx in mempool", "sdk", 19, ErrBroadcastTxInMempool, BroadcastActionNone},
+		{"mempool is full", "sdk", 20, ErrBroadcastMempoolIsFull, BroadcastActionRetry},
+		{"unauthorized", "sdk", 4, ErrBroadcastUnauthorized, BroadcastActionGiveUp},
+		{"unknown code", "sdk", 5, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"empty codespace", "", 32, ErrBroadcastWrongSequence, BroadcastActionRetry},
+		{"insufficient fee of other codespace", "exchange", 13, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"out of gas of other codespace", "exchange", 11, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"wrong sequence of other codespace", "exchange", 32, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"tx in mempool of other codespace", "exchange", 19, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"mempool is full of other codespace", "exchange", 20, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+		{"unauthorized of other codespace", "exchange", 4, ErrBroadcastTxRejected, BroadcastActionGiveUp},
+	}
+	for _, test := range tests {
+		err, action := classifyBroadcastCode(test.codespace, test.code)
+		if err != test.err || action != test.action {
+			t.Errorf("%v: classify mismatch, have (%v, %v) want (%v, %v)", test.name, err, action, test.err, test.action)
+		}
+
+		// sendtx invalidates and releases sequences by the classified error
+		broadcastErr := NewBroadcastError(&TxResponse{Codespace: test.codespace, Code: test.code})
+		if !errors.Is(broadcastErr, test.err) || !errors.Is(broadcastErr, tokens.ErrBroadcastTx) {
+			t.Errorf("%v: broadcast error %v is not %v", test.name, broadcastErr, test.err)
+		}
+		if GetBroadcastAction(broadcastErr) != test.action {
+			t.Errorf("%v: broadcast action mismatch, have %v want %v", test.name, GetBroadcastAction(broadcastErr), test.action)
+		}
+	}
+}
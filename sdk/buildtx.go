@@ -1,6 +1,9 @@
 package sdk
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -13,6 +16,7 @@ import (
 	"github.com/anyswap/CrossChain-Router/v3/params"
 	"github.com/anyswap/CrossChain-Router/v3/router"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	cosmosClient "github.com/cosmos/cosmos-sdk/client"
 )
 
 var (
@@ -21,64 +25,61 @@ var (
 	DefaultGasLimit  uint64 = 150000
 	DefaultFee              = "500"
 
-	cachedAccountNumberMap = make(map[string]uint64)
+	// DefaultGasAdjustment multiplier applied to simulated gas used
+	DefaultGasAdjustment = 1.3
+
+	// custom config keys (chainID,customKey => customValue)
+	gasAdjustmentKey = "GasAdjustment"
+	minGasLimitKey   = "MinGasLimit"
 
 	numberPattern = regexp.MustCompile(`^\d+(?:.\d+)?$`)
 )
 
 // BuildRawTransaction build raw tx
-//
-//nolint:gocyclo // ok
 func (b *Bridge) BuildRawTransaction(args *tokens.BuildTxArgs) (rawTx interface{}, err error) {
-	if !params.IsTestMode && args.ToChainID.String() != b.ChainConfig.ChainID {
-		return nil, tokens.ErrToChainIDMismatch
-	}
-	if args.Input != nil {
-		return nil, fmt.Errorf("forbid build raw swap tx with input data")
-	}
-	if args.From == "" {
-		return nil, fmt.Errorf("forbid empty sender")
-	}
+	return b.BuildRawTransactionWithContext(baseCtx, args)
+}
 
-	routerMPC, err := router.GetRouterMPC(args.GetTokenID(), b.ChainConfig.ChainID)
+// BuildRawTransactionWithContext build raw tx with context
+//
+//nolint:gocyclo // ok
+func (b *Bridge) BuildRawTransactionWithContext(ctx context.Context, args *tokens.BuildTxArgs) (rawTx interface{}, err error) {
+	multichainToken, err := b.checkBuildTxArgs(args)
 	if err != nil {
 		return nil, err
 	}
-	if !common.IsEqualIgnoreCase(args.From, routerMPC) {
-		log.Error("build tx mpc mismatch", "have", args.From, "want", routerMPC)
-		return nil, tokens.ErrSenderMismatch
-	}
-
-	mpcPubkey := router.GetMPCPublicKey(args.From)
-	if mpcPubkey == "" {
-		return nil, tokens.ErrMissMPCPublicKey
-	}
-
-	erc20SwapInfo := args.ERC20SwapInfo
-	multichainToken := router.GetCachedMultichainToken(erc20SwapInfo.TokenID, args.ToChainID.String())
-	if multichainToken == "" {
-		log.Warn("get multichain token failed", "tokenID", erc20SwapInfo.TokenID, "chainID", args.ToChainID)
-		return nil, tokens.ErrMissTokenConfig
-	}
-
-	tokenCfg := b.GetTokenConfig(multichainToken)
-	if tokenCfg == nil {
-		return nil, tokens.ErrMissTokenConfig
-	}
 
 	if receiver, amount, err := b.getReceiverAndAmount(args, multichainToken); err != nil {
 		return nil, err
 	} else {
 		args.SwapValue = amount // SwapValue
-		if extra, err := b.initExtra(args); err != nil {
+		needEstimateGas := args.Extra == nil || args.Extra.Gas == nil
+		needCalcFee := args.Extra == nil || args.Extra.Fee == nil
+		needReserveSeq := args.Extra == nil || args.Extra.Sequence == nil
+		if extra, err := b.initExtra(ctx, args); err != nil {
 			return nil, err
 		} else {
+			defer func() {
+				if rawTx == nil && needReserveSeq {
+					b.releaseSeq(args)
+				}
+			}()
 			memo := args.GetUniqueSwapIdentifier()
 			mpcPubkey := router.GetMPCPublicKey(args.From)
-			if txBuilder, err := b.BuildTx(args, receiver, multichainToken, memo, mpcPubkey, amount); err != nil {
+			if txBuilder, err := b.BuildTx(ctx, args, receiver, multichainToken, memo, mpcPubkey, amount); err != nil {
 				return nil, err
 			} else {
-				accountNumber, err := b.GetAccountNum(args.From)
+				if needEstimateGas {
+					if err := b.adjustGasLimit(ctx, args, txBuilder); err != nil {
+						return nil, err
+					}
+					if needCalcFee {
+						if err := b.adjustFee(ctx, args, txBuilder); err != nil {
+							return nil, err
+						}
+					}
+				}
+				accountNumber, err := b.GetAccountNumWithContext(ctx, args.From)
 				if err != nil {
 					return nil, err
 				}
@@ -106,27 +107,174 @@ func (b *Bridge) BuildRawTransaction(args *tokens.BuildTxArgs) (rawTx interface{
 	}
 }
 
-func (b *Bridge) initExtra(args *tokens.BuildTxArgs) (extra *tokens.AllExtras, err error) {
+// checkBuildTxArgs check build tx args, returns the multichain token (denom) on this chain
+func (b *Bridge) checkBuildTxArgs(args *tokens.BuildTxArgs) (multichainToken string, err error) {
+	if !params.IsTestMode && args.ToChainID.String() != b.ChainConfig.ChainID {
+		return "", tokens.ErrToChainIDMismatch
+	}
+	if args.Input != nil {
+		return "", fmt.Errorf("forbid build raw swap tx with input data")
+	}
+	if args.From == "" {
+		return "", fmt.Errorf("forbid empty sender")
+	}
+
+	routerMPC, err := router.GetRouterMPC(args.GetTokenID(), b.ChainConfig.ChainID)
+	if err != nil {
+		return "", err
+	}
+	if !common.IsEqualIgnoreCase(args.From, routerMPC) {
+		log.Error("build tx mpc mismatch", "have", args.From, "want", routerMPC)
+		return "", tokens.ErrSenderMismatch
+	}
+
+	mpcPubkey := router.GetMPCPublicKey(args.From)
+	if mpcPubkey == "" {
+		return "", tokens.ErrMissMPCPublicKey
+	}
+
+	erc20SwapInfo := args.ERC20SwapInfo
+	multichainToken = router.GetCachedMultichainToken(erc20SwapInfo.TokenID, args.ToChainID.String())
+	if multichainToken == "" {
+		log.Warn("get multichain token failed", "tokenID", erc20SwapInfo.TokenID, "chainID", args.ToChainID)
+		return "", tokens.ErrMissTokenConfig
+	}
+
+	tokenCfg := b.GetTokenConfig(multichainToken)
+	if tokenCfg == nil {
+		return "", tokens.ErrMissTokenConfig
+	}
+	return multichainToken, nil
+}
+
+func (b *Bridge) initExtra(ctx context.Context, args *tokens.BuildTxArgs) (extra *tokens.AllExtras, err error) {
 	extra = args.Extra
 	if extra == nil {
 		extra = &tokens.AllExtras{}
 		args.Extra = extra
 	}
-	if extra.Sequence == nil {
-		if extra.Sequence, err = b.GetSeq(args); err != nil {
+	needReserveSeq := extra.Sequence == nil
+	if needReserveSeq {
+		if extra.Sequence, err = b.GetSeq(ctx, args); err != nil {
 			return nil, err
 		}
 	}
 	if extra.Gas == nil {
-		extra.Gas = &DefaultGasLimit
+		gasLimit := b.getDefaultGasLimit()
+		extra.Gas = &gasLimit
 	}
 	if extra.Fee == nil {
-		fee := b.getDefaultFee()
+		fee, err := b.getFee(ctx, args, *extra.Gas)
+		if err != nil {
+			if needReserveSeq {
+				b.releaseSeq(args)
+			}
+			return nil, err
+		}
 		extra.Fee = &fee
 	}
 	return extra, nil
 }
 
+// adjustGasLimit simulate the unsigned tx and set gas limit to the adjusted gas used
+func (b *Bridge) adjustGasLimit(ctx context.Context, args *tokens.BuildTxArgs, txBuilder cosmosClient.TxBuilder) error {
+	gasUsed, err := b.EstimateGas(ctx, txBuilder)
+	if err != nil {
+		log.Warn("estimate gas failed, use default gas limit", "swapID", args.SwapID, "gasLimit", *args.Extra.Gas, "err", err)
+		return nil
+	}
+	esGasLimit := uint64(float64(gasUsed) * b.getGasAdjustment())
+	if minGasLimit := b.getMinGasLimit(); esGasLimit < minGasLimit {
+		esGasLimit = minGasLimit
+	}
+	maxGasLimit := params.GetMaxGasLimit(b.ChainConfig.ChainID)
+	if maxGasLimit > 0 && esGasLimit > maxGasLimit {
+		log.Warn(fmt.Sprintf("build %s tx estimated gas is too large", args.SwapType.String()),
+			"swapID", args.SwapID, "from", args.From, "gasUsed", gasUsed,
+			"gasLimit", esGasLimit, "maxGasLimit", maxGasLimit)
+		return fmt.Errorf("%w %v on chain %v", tokens.ErrBuildTxErrorAndDelay, "estimated gas is too large", b.ChainConfig.ChainID)
+	}
+	log.Info("estimate gas success", "swapID", args.SwapID, "gasUsed", gasUsed, "gasLimit", esGasLimit)
+	args.Extra.Gas = &esGasLimit
+	txBuilder.SetGasLimit(esGasLimit)
+	return nil
+}
+
+// adjustFee recalc fee after gas limit is adjusted
+func (b *Bridge) adjustFee(ctx context.Context, args *tokens.BuildTxArgs, txBuilder cosmosClient.TxBuilder) error {
+	fee, err := b.getFee(ctx, args, *args.Extra.Gas)
+	if err != nil {
+		return err
+	}
+	feeCoins, err := ParseCoinsFee(fee)
+	if err != nil {
+		return err
+	}
+	args.Extra.Fee = &fee
+	txBuilder.SetFeeAmount(feeCoins)
+	return nil
+}
+
+// EstimateGas simulate tx (signature can be empty) and returns gas used
+func (b *Bridge) EstimateGas(ctx context.Context, txBuilder cosmosClient.TxBuilder) (uint64, error) {
+	txBytes, err := b.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return 0, err
+	}
+	res, err := b.SimulateTx(ctx, &SimulateRequest{
+		TxBytes: base64.StdEncoding.EncodeToString(txBytes),
+	})
+	if err != nil {
+		return 0, err
+	}
+	var simRes *SimulateResponse
+	if err := json.Unmarshal([]byte(res), &simRes); err != nil {
+		return 0, err
+	}
+	if simRes == nil || simRes.GasInfo == nil {
+		return 0, tokens.ErrSimulateTx
+	}
+	gasUsed, err := strconv.ParseUint(simRes.GasInfo.GasUsed, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if gasUsed == 0 {
+		return 0, tokens.ErrSimulateTx
+	}
+	return gasUsed, nil
+}
+
+func (b *Bridge) getDefaultGasLimit() uint64 {
+	gasLimit := DefaultGasLimit
+	serverCfg := params.GetRouterServerConfig()
+	if serverCfg != nil {
+		if cfgGasLimit, exist := serverCfg.DefaultGasLimit[b.ChainConfig.ChainID]; exist {
+			gasLimit = cfgGasLimit
+		}
+	}
+	return gasLimit
+}
+
+func (b *Bridge) getGasAdjustment() float64 {
+	if cfgValue := params.GetCustom(b.ChainConfig.ChainID, gasAdjustmentKey); cfgValue != "" {
+		if adjustment, err := strconv.ParseFloat(cfgValue, 64); err == nil && adjustment > 0 {
+			return adjustment
+		}
+		log.Warn("wrong gas adjustment config", "chainID", b.ChainConfig.ChainID, "value", cfgValue)
+	}
+	return DefaultGasAdjustment
+}
+
+func (b *Bridge) getMinGasLimit() uint64 {
+	if cfgValue := params.GetCustom(b.ChainConfig.ChainID, minGasLimitKey); cfgValue != "" {
+		if minGasLimit, err := strconv.ParseUint(cfgValue, 10, 64); err == nil {
+			return minGasLimit
+		}
+		log.Warn("wrong min gas limit config", "chainID", b.ChainConfig.ChainID, "value", cfgValue)
+	}
+	return 0
+}
+
 func (b *Bridge) getDefaultFee() string {
 	fee := DefaultFee
 	serverCfg := params.GetRouterServerConfig()
@@ -146,23 +294,21 @@ func is_numeric(word string) bool {
 }
 
 // GetPoolNonce impl NonceSetter interface
-func (b *Bridge) GetPoolNonce(address, _height string) (uint64, error) {
-	if acc, err := b.GetBaseAccount(address); err != nil {
-		return 0, err
-	} else {
-		if acc != nil {
-			if sequence, err := strconv.ParseUint(acc.Account.Sequence, 10, 64); err == nil {
-				return sequence, nil
-			} else {
-				return 0, err
-			}
-		}
-		return 0, tokens.ErrRPCQueryError
+func (b *Bridge) GetPoolNonce(address, height string) (uint64, error) {
+	return b.GetPoolNonceWithContext(baseCtx, address, height)
+}
+
+// GetPoolNonceWithContext get pool nonce with context
+func (b *Bridge) GetPoolNonceWithContext(ctx context.Context, address, _height string) (uint64, error) {
+	if sequence, ok := b.AccountCache.GetSequence(address); ok {
+		return sequence, nil
 	}
+	_, sequence, err := b.loadAccountState(ctx, address)
+	return sequence, err
 }
 
 // GetSeq returns account tx sequence
-func (b *Bridge) GetSeq(args *tokens.BuildTxArgs) (nonceptr *uint64, err error) {
+func (b *Bridge) GetSeq(ctx context.Context, args *tokens.BuildTxArgs) (nonceptr *uint64, err error) {
 	var nonce uint64
 
 	if params.IsAutoSwapNonceEnabled(b.ChainConfig.ChainID) { // increase automatically
@@ -171,7 +317,7 @@ func (b *Bridge) GetSeq(args *tokens.BuildTxArgs) (nonceptr *uint64, err error)
 	}
 
 	for i := 0; i < retryRPCCount; i++ {
-		nonce, err = b.GetPoolNonce(args.From, "pending")
+		nonce, err = b.GetPoolNonceWithContext(ctx, args.From, "pending")
 		if err == nil {
 			break
 		}
@@ -180,35 +326,62 @@ func (b *Bridge) GetSeq(args *tokens.BuildTxArgs) (nonceptr *uint64, err error)
 	if err != nil {
 		return nil, err
 	}
-	nonce = b.AdjustNonce(args.From, nonce)
+	minNonce := b.AdjustNonce(args.From, nonce)
+	nonce = b.SequenceManager.Reserve(args.From, args.GetUniqueSwapIdentifier(), nonce, minNonce)
 	return &nonce, nil
 }
 
+// releaseSeq release the sequence reserved by GetSeq if building tx failed
+func (b *Bridge) releaseSeq(args *tokens.BuildTxArgs) {
+	if args.Extra == nil || args.Extra.Sequence == nil {
+		return
+	}
+	if params.IsAutoSwapNonceEnabled(b.ChainConfig.ChainID) {
+		return
+	}
+	log.Info("release sequence of failed build", "swapID", args.SwapID, "from", args.From, "sequence", *args.Extra.Sequence)
+	b.SequenceManager.Release(args.From, *args.Extra.Sequence)
+	args.Extra.Sequence = nil
+}
+
 // GetAccountNum get account number
 func (b *Bridge) GetAccountNum(account string) (uint64, error) {
-	if accNo := cachedAccountNumberMap[account]; accNo > 0 {
+	return b.GetAccountNumWithContext(baseCtx, account)
+}
+
+// GetAccountNumWithContext get account number with context
+func (b *Bridge) GetAccountNumWithContext(ctx context.Context, account string) (uint64, error) {
+	if accNo, ok := b.AccountCache.GetAccountNumber(account); ok {
 		return accNo, nil
 	}
-	if acc, err := b.GetBaseAccount(account); err != nil {
-		return 0, err
-	} else {
-		if acc != nil {
-			if accountNumber, err := strconv.ParseUint(acc.Account.AccountNumber, 10, 64); err == nil {
-				cachedAccountNumberMap[account] = accountNumber
-				return accountNumber, nil
-			} else {
-				return 0, err
-			}
-		}
-		return 0, tokens.ErrRPCQueryError
+	accountNumber, _, err := b.loadAccountState(ctx, account)
+	return accountNumber, err
+}
+
+// loadAccountState query account number and sequence and cache them
+func (b *Bridge) loadAccountState(ctx context.Context, address string) (accountNumber, sequence uint64, err error) {
+	acc, err := b.GetBaseAccount(ctx, address)
+	if err != nil {
+		return 0, 0, err
+	}
+	if acc == nil {
+		return 0, 0, tokens.ErrRPCQueryError
+	}
+	if accountNumber, err = strconv.ParseUint(acc.Account.AccountNumber, 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if sequence, err = strconv.ParseUint(acc.Account.Sequence, 10, 64); err != nil {
+		return 0, 0, err
 	}
+	b.AccountCache.Set(address, accountNumber, sequence)
+	return accountNumber, sequence, nil
 }
 
 func (b *Bridge) getReceiverAndAmount(args *tokens.BuildTxArgs, multichainToken string) (receiver string, amount *big.Int, err error) {
 	erc20SwapInfo := args.ERC20SwapInfo
 	receiver = args.Bind
-	if !b.IsValidAddress(receiver) {
-		log.Warn("swapout to wrong receiver", "receiver", args.Bind)
+	if !b.IsValidReceiver(multichainToken, receiver) {
+		log.Warn("swapout to wrong receiver", "receiver", args.Bind, "token", multichainToken)
 		return receiver, amount, errors.New("swapout to invalid receiver")
 	}
 	fromBridge := router.GetBridgeByChainID(args.FromChainID.String())
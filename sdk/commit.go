@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+)
+
+var (
+	// CommitPollInterval interval of querying whether sent tx is included in block
+	CommitPollInterval = 1 * time.Second
+
+	// ErrWaitTxCommittedTimeout tx is not included in block before deadline
+	ErrWaitTxCommittedTimeout = errors.New("wait tx committed timeout")
+)
+
+// TxCommitResult result of tx included in block
+type TxCommitResult struct {
+	TxHash    string `json:"txhash"`
+	Height    uint64 `json:"height"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	RawLog    string `json:"rawLog,omitempty"`
+	GasWanted uint64 `json:"gasWanted"`
+	GasUsed   uint64 `json:"gasUsed"`
+}
+
+// WaitTxCommitted poll tx by hash until it is included in block, timeout or ctx is done.
+// returns the result and `tokens.ErrTxWithWrongStatus` if the tx is executed failed.
+func (b *Bridge) WaitTxCommitted(ctx context.Context, txHash string, timeout time.Duration) (*TxCommitResult, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		res, err := b.GetTransactionByHash(ctx, txHash)
+		if err == nil && res != nil && res.TxResponse != nil {
+			result := newTxCommitResult(res.TxResponse)
+			if result.Height > 0 {
+				log.Info("tx is committed", "txhash", txHash, "height", result.Height,
+					"code", result.Code, "gasWanted", result.GasWanted, "gasUsed", result.GasUsed)
+				if result.Code != 0 {
+					return result, fmt.Errorf("%w, codespace: %v, code: %v, %v",
+						tokens.ErrTxWithWrongStatus, result.Codespace, result.Code, result.RawLog)
+				}
+				return result, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			log.Warn("wait tx committed timeout", "txhash", txHash, "timeout", timeout, "err", err)
+			return nil, ErrWaitTxCommittedTimeout
+		}
+		select {
+		case <-ctx.Done():
+			log.Warn("wait tx committed canceled", "txhash", txHash, "err", ctx.Err())
+			return nil, ctx.Err()
+		case <-time.After(CommitPollInterval):
+		}
+	}
+}
+
+func newTxCommitResult(res *TxResponse) *TxCommitResult {
+	height, _ := strconv.ParseUint(res.Height, 10, 64)
+	gasWanted, _ := strconv.ParseUint(res.GasWanted, 10, 64)
+	gasUsed, _ := strconv.ParseUint(res.GasUsed, 10, 64)
+	return &TxCommitResult{
+		TxHash:    res.TxHash,
+		Height:    height,
+		Code:      res.Code,
+		Codespace: res.Codespace,
+		RawLog:    res.RawLog,
+		GasWanted: gasWanted,
+		GasUsed:   gasUsed,
+	}
+}
@@ -0,0 +1,141 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/rpc/client"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+var (
+	// DefaultQueryTimeout default timeout of one query to a gateway node
+	DefaultQueryTimeout = 30 * time.Second
+	// SimulateTxTimeout default timeout of simulating tx
+	SimulateTxTimeout = 120 * time.Second
+
+	// baseCtx is the parent context of queries without request context,
+	// all queries are canceled when it's canceled on shutdown.
+	baseCtx, cancelBaseCtx = context.WithCancel(context.Background())
+)
+
+const maxReadContentLength int64 = 1024 * 1024 * 10 // 10M
+
+func init() {
+	go func() {
+		<-utils.CleanupChan
+		cancelBaseCtx()
+	}()
+}
+
+// BaseContext the context canceled on shutdown, used by queries without request context
+func BaseContext() context.Context {
+	return baseCtx
+}
+
+// queryTimeout timeout of one query of method to a gateway node
+func queryTimeout(method string) time.Duration {
+	cfg := config.GetServerConfig().QueryConfig
+	if cfg != nil {
+		if seconds := cfg.MethodTimeoutSeconds[method]; seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	switch method {
+	case "SimulateTx":
+		return SimulateTxTimeout
+	case "BroadcastTx":
+		return BroadcastTimeout
+	}
+	if cfg != nil && cfg.DefaultTimeoutSeconds > 0 {
+		return time.Duration(cfg.DefaultTimeoutSeconds) * time.Second
+	}
+	return DefaultQueryTimeout
+}
+
+// withQueryTimeout derive the context of one query of method to a gateway node,
+// it's canceled if timeout, the parent (eg. http request) is done, or on shutdown.
+func withQueryTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = baseCtx
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, queryTimeout(method))
+	if ctx != baseCtx {
+		go func() {
+			select {
+			case <-baseCtx.Done():
+				cancel()
+			case <-queryCtx.Done():
+			}
+		}()
+	}
+	return queryCtx, cancel
+}
+
+// restGet get json result from rest api with context
+func restGet(ctx context.Context, method string, result interface{}, url string) error {
+	ctx, cancel := withQueryTimeout(ctx, method)
+	defer cancel()
+	resp, err := client.HTTPGetWithContext(ctx, url, nil, nil, timeoutSeconds(method))
+	if err != nil {
+		return fmt.Errorf("GET request error: %w (url: %v)", err, url)
+	}
+	body, err := readResponse(resp, url)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unmarshal result error: %w", err)
+	}
+	return nil
+}
+
+// restPost post json data to rest api with context, returns the response body
+func restPost(ctx context.Context, method, url string, data []byte) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx, method)
+	defer cancel()
+	resp, err := client.HTTPPostWithContext(ctx, url, json.RawMessage(data), nil, nil, timeoutSeconds(method))
+	if err != nil {
+		return "", fmt.Errorf("POST request error: %w (url: %v)", err, url)
+	}
+	body, err := readResponse(resp, url)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// responseStatusError error of response with non-ok status
+type responseStatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *responseStatusError) Error() string {
+	return fmt.Sprintf("error response status: %v (url: %v)", e.StatusCode, e.URL)
+}
+
+func readResponse(resp *http.Response, url string) ([]byte, error) {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &responseStatusError{StatusCode: resp.StatusCode, URL: url}
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReadContentLength))
+	if err != nil {
+		return nil, fmt.Errorf("read body error: %w", err)
+	}
+	return body, nil
+}
+
+// timeoutSeconds http client timeout of method, the context deadline is the precise one
+func timeoutSeconds(method string) int {
+	return int((queryTimeout(method) + time.Second - 1) / time.Second)
+}
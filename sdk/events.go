@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// MsgIndexAttributeKey message index attribute of events (sdk 0.50+)
+	MsgIndexAttributeKey = "msg_index"
+
+	MessageType = "message"
+)
+
+// Event tx event, attributes are plain strings since sdk 0.47
+type Event struct {
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes"`
+}
+
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Index bool   `json:"index,omitempty"`
+}
+
+// Transfer bank transfer of message
+type Transfer struct {
+	Sender    string
+	Recipient string
+	Amount    string
+}
+
+// MessageLogs get per message logs.
+// newer chains (sdk 0.50+) return empty logs, then build the logs from `msg_index` attributes of events.
+func (r *TxResponse) MessageLogs() sdk.ABCIMessageLogs {
+	if len(r.Logs) > 0 || len(r.Events) == 0 {
+		return r.Logs
+	}
+	return BuildMessageLogs(r.Events)
+}
+
+// BuildMessageLogs build per message logs from tx events.
+// events without `msg_index` (eg. fee, signature) are tx level and ignored.
+// events are kept separately (not merged by type as in the logs of older chains),
+// so that each `transfer` event is exactly one transfer.
+func BuildMessageLogs(events []Event) sdk.ABCIMessageLogs {
+	var logs sdk.ABCIMessageLogs
+	for _, event := range events {
+		msgIndex := -1
+		attrs := make([]sdk.Attribute, 0, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			if attr.Key == MsgIndexAttributeKey {
+				if index, err := strconv.Atoi(attr.Value); err == nil {
+					msgIndex = index
+				}
+				continue
+			}
+			attrs = append(attrs, sdk.NewAttribute(attr.Key, attr.Value))
+		}
+		if msgIndex < 0 {
+			continue
+		}
+		for len(logs) <= msgIndex {
+			logs = append(logs, sdk.ABCIMessageLog{MsgIndex: uint32(len(logs))})
+		}
+		logs[msgIndex].Events = append(logs[msgIndex].Events, sdk.StringEvent{Type: event.Type, Attributes: attrs})
+	}
+	return logs
+}
+
+// GetTransfers get bank transfers in message log.
+// a `transfer` event with one recipient is one transfer, its attributes are matched by key.
+// the legacy logs merge the transfers of a message into one event,
+// then a new transfer starts at each `recipient` (the attribute order is recipient, sender, amount).
+// the sender is from the message event if the transfer event has no sender (legacy format).
+func GetTransfers(messageLog sdk.ABCIMessageLog) []*Transfer {
+	var transfers []*Transfer
+	var msgSender string
+	for _, event := range messageLog.Events {
+		switch event.Type {
+		case MessageType:
+			for _, attr := range event.Attributes {
+				if attr.Key == "sender" && msgSender == "" {
+					msgSender = attr.Value
+				}
+			}
+		case TransferType:
+			switch countAttributes(event, "recipient") {
+			case 0:
+			case 1:
+				transfers = append(transfers, parseTransferEvent(event))
+			default:
+				transfers = append(transfers, parseMergedTransferEvent(event)...)
+			}
+		}
+	}
+	for _, transfer := range transfers {
+		if transfer.Sender == "" {
+			transfer.Sender = msgSender
+		}
+	}
+	return transfers
+}
+
+func countAttributes(event sdk.StringEvent, key string) int {
+	count := 0
+	for _, attr := range event.Attributes {
+		if attr.Key == key {
+			count++
+		}
+	}
+	return count
+}
+
+// parseTransferEvent parse event of one transfer by attribute keys
+func parseTransferEvent(event sdk.StringEvent) *Transfer {
+	transfer := &Transfer{}
+	for _, attr := range event.Attributes {
+		switch attr.Key {
+		case "recipient":
+			transfer.Recipient = attr.Value
+		case "sender":
+			transfer.Sender = attr.Value
+		case "amount":
+			transfer.Amount = attr.Value
+		}
+	}
+	return transfer
+}
+
+// parseMergedTransferEvent parse legacy event of merged transfers by attribute positions
+func parseMergedTransferEvent(event sdk.StringEvent) []*Transfer {
+	var transfers []*Transfer
+	var curr *Transfer
+	for _, attr := range event.Attributes {
+		switch attr.Key {
+		case "recipient":
+			curr = &Transfer{Recipient: attr.Value}
+			transfers = append(transfers, curr)
+		case "sender":
+			if curr != nil {
+				curr.Sender = attr.Value
+			}
+		case "amount":
+			if curr != nil {
+				curr.Amount = attr.Value
+			}
+		}
+	}
+	return transfers
+}
+
+// LogsToEvents flatten events in message logs
+func LogsToEvents(logs sdk.ABCIMessageLogs) []Event {
+	var events []Event
+	for _, messageLog := range logs {
+		for _, event := range messageLog.Events {
+			attrs := make([]EventAttribute, 0, len(event.Attributes))
+			for _, attr := range event.Attributes {
+				attrs = append(attrs, EventAttribute{Key: attr.Key, Value: attr.Value})
+			}
+			events = append(events, Event{Type: event.Type, Attributes: attrs})
+		}
+	}
+	return events
+}
@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/params"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	// custom config keys (chainID,customKey => customValue)
+	gasPriceKey          = "GasPrice"
+	queryMinGasPricesKey = "QueryMinGasPrices"
+)
+
+// getFee calc tx fee, fee is gasLimit * gasPrice if gas price is configured,
+// otherwise use the default fee. fee is bumped for replacing swaps.
+func (b *Bridge) getFee(ctx context.Context, args *tokens.BuildTxArgs, gasLimit uint64) (string, error) {
+	addPercent := b.getPlusFeePercent(args)
+	gasPrice, err := b.getGasPrice(ctx)
+	if err != nil {
+		return "", err
+	}
+	if gasPrice == nil {
+		fee := b.getDefaultFee()
+		if addPercent == 0 {
+			return fee, nil
+		}
+		feeCoins, err := ParseCoinsFee(fee)
+		if err != nil {
+			return "", err
+		}
+		for i, coin := range feeCoins {
+			amount := coin.Amount.MulRaw(int64(100 + addPercent)).QuoRaw(100)
+			feeCoins[i] = sdk.NewCoin(coin.Denom, amount)
+		}
+		return feeCoins.String(), nil
+	}
+
+	if addPercent > 0 {
+		gasPrice.Amount = gasPrice.Amount.MulInt64(int64(100 + addPercent)).QuoInt64(100)
+	}
+	if maxGasPrice := params.GetMaxGasPrice(b.ChainConfig.ChainID); maxGasPrice != nil {
+		if gasPrice.Amount.GT(sdk.NewDecFromBigInt(maxGasPrice)) {
+			log.Warn("gas price exceeded maximum limit", "chainID", b.ChainConfig.ChainID, "gasPrice", gasPrice, "max", maxGasPrice)
+			return "", fmt.Errorf("gas price %v exceeded config maximum limit", gasPrice)
+		}
+	}
+	feeAmount := gasPrice.Amount.MulInt64(int64(gasLimit)).Ceil().TruncateInt()
+	fee := sdk.NewCoin(gasPrice.Denom, feeAmount).String()
+	log.Info("calc tx fee", "swapID", args.SwapID, "gasLimit", gasLimit, "gasPrice", gasPrice, "replaceNum", args.GetReplaceNum(), "fee", fee)
+	return fee, nil
+}
+
+// getGasPrice get configured gas price, raised to the node minimum gas price if enabled.
+// returns nil if gas price is not configured.
+// injective has no fee market module for cosmos txs, so there is no fee market source.
+func (b *Bridge) getGasPrice(ctx context.Context) (*sdk.DecCoin, error) {
+	var gasPrice *sdk.DecCoin
+	if cfgValue := params.GetCustom(b.ChainConfig.ChainID, gasPriceKey); cfgValue != "" {
+		price, err := b.parseGasPrice(cfgValue)
+		if err != nil {
+			return nil, fmt.Errorf("wrong gas price config %v: %w", cfgValue, err)
+		}
+		gasPrice = &price
+	} else if fixedGasPrice := params.GetFixedGasPrice(b.ChainConfig.ChainID); fixedGasPrice != nil {
+		price := sdk.NewDecCoinFromDec(b.Denom, sdk.NewDecFromBigInt(fixedGasPrice))
+		gasPrice = &price
+	}
+
+	queryMinGasPrices, _ := strconv.ParseBool(params.GetCustom(b.ChainConfig.ChainID, queryMinGasPricesKey))
+	if !queryMinGasPrices {
+		return gasPrice, nil
+	}
+	minGasPrices, err := b.GetMinGasPrices(ctx)
+	if err != nil {
+		log.Warn("get min gas prices failed", "chainID", b.ChainConfig.ChainID, "err", err)
+		return gasPrice, nil
+	}
+	denom := b.Denom
+	if gasPrice != nil {
+		denom = gasPrice.Denom
+	}
+	minGasPrice := minGasPrices.AmountOf(denom)
+	if gasPrice == nil || gasPrice.Amount.LT(minGasPrice) {
+		if minGasPrice.IsZero() {
+			return gasPrice, nil
+		}
+		price := sdk.NewDecCoinFromDec(denom, minGasPrice)
+		gasPrice = &price
+	}
+	return gasPrice, nil
+}
+
+func (b *Bridge) parseGasPrice(price string) (sdk.DecCoin, error) {
+	if is_numeric(price) {
+		price += b.Denom
+	}
+	return sdk.ParseDecCoin(price)
+}
+
+// getPlusFeePercent get percent to bump fee when replacing swap
+func (b *Bridge) getPlusFeePercent(args *tokens.BuildTxArgs) uint64 {
+	replaceNum := args.GetReplaceNum()
+	serverCfg := params.GetRouterServerConfig()
+	if replaceNum == 0 || serverCfg == nil {
+		return 0
+	}
+	addPercent := replaceNum * serverCfg.ReplacePlusGasPricePercent
+	if serverCfg.MaxPlusGasPricePercentage > 0 && addPercent > serverCfg.MaxPlusGasPricePercentage {
+		addPercent = serverCfg.MaxPlusGasPricePercentage
+	}
+	return addPercent
+}
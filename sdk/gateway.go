@@ -0,0 +1,352 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/metrics"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"google.golang.org/grpc/status"
+)
+
+// gateway kinds
+const (
+	GatewayKindGRPC = "grpc"
+	GatewayKindREST = "rest"
+)
+
+var (
+	// GatewayFailureThreshold open the circuit after consecutive failures
+	GatewayFailureThreshold = 3
+	// GatewayMaxLagBlocks open the circuit if the node lags behind the max height too much
+	GatewayMaxLagBlocks uint64 = 30
+	// GatewayOpenDuration minimum duration of removing failing node
+	GatewayOpenDuration = 60 * time.Second
+	// GatewayReadmitProbes re-admit failing node after consecutive successful probes
+	GatewayReadmitProbes = 2
+	// GatewayProbeInterval interval of probing all nodes
+	GatewayProbeInterval = 10 * time.Second
+
+	gatewayHealth = &gatewayHealthTracker{
+		nodes: make(map[string]*GatewayStatus),
+	}
+)
+
+// GatewayStatus health status of gateway node
+type GatewayStatus struct {
+	URL                 string        `json:"url"`
+	Label               string        `json:"label"` // metrics label, without credentials of url
+	Kind                string        `json:"kind"`
+	Available           bool          `json:"available"` // false if circuit is open
+	Height              uint64        `json:"height"`
+	Lag                 uint64        `json:"lag"`
+	Latency             time.Duration `json:"latency"`   // moving average
+	ErrorRate           float64       `json:"errorRate"` // moving average
+	Requests            uint64        `json:"requests"`
+	Failures            uint64        `json:"failures"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	SuccessfulProbes    int           `json:"successfulProbes"` // consecutive successful probes since opened
+	OpenedAt            time.Time     `json:"openedAt,omitempty"`
+	LastError           string        `json:"lastError,omitempty"`
+	LastCheck           time.Time     `json:"lastCheck"`
+}
+
+type gatewayHealthTracker struct {
+	lock  sync.RWMutex
+	nodes map[string]*GatewayStatus
+	order []string // config order
+
+	activeGRPC []*grpcClient
+	activeREST []string
+}
+
+// initGatewayHealth register gateway nodes, all nodes are available initially
+func (b *Bridge) initGatewayHealth() {
+	t := gatewayHealth
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.nodes = make(map[string]*GatewayStatus)
+	t.order = nil
+	for _, url := range b.GatewayConfig.GRPCAPIAddress {
+		if _, exist := rpcClientsMap[url]; exist {
+			t.addNode(url, GatewayKindGRPC)
+		}
+	}
+	for _, url := range b.AllGatewayURLs {
+		t.addNode(url, GatewayKindREST)
+	}
+	t.updateActive()
+}
+
+func (t *gatewayHealthTracker) addNode(url, kind string) {
+	if _, exist := t.nodes[url]; exist {
+		return
+	}
+	label := gatewayLabel(len(t.order), url)
+	t.nodes[url] = &GatewayStatus{URL: url, Label: label, Kind: kind, Available: true}
+	t.order = append(t.order, url)
+}
+
+// gatewayLabel label gateway by index and host,
+// the url path, query and user info are dropped as they may contain api keys
+func gatewayLabel(index int, rawURL string) string {
+	host := ""
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+	return fmt.Sprintf("%d-%s", index, host)
+}
+
+// grpcClients get available grpc clients
+func grpcClients() []*grpcClient {
+	gatewayHealth.lock.RLock()
+	defer gatewayHealth.lock.RUnlock()
+	if len(gatewayHealth.activeGRPC) == 0 {
+		return rpcClients
+	}
+	return gatewayHealth.activeGRPC
+}
+
+// restEndpoints get available rest gateway urls
+func (b *Bridge) restEndpoints() []string {
+	gatewayHealth.lock.RLock()
+	defer gatewayHealth.lock.RUnlock()
+	if len(gatewayHealth.activeREST) == 0 {
+		return b.AllGatewayURLs
+	}
+	return gatewayHealth.activeREST
+}
+
+// grpcEndpoints get urls of available grpc clients
+func grpcEndpoints() []string {
+	gatewayHealth.lock.RLock()
+	defer gatewayHealth.lock.RUnlock()
+	var urls []string
+	for _, url := range gatewayHealth.order {
+		if node := gatewayHealth.nodes[url]; node.Kind == GatewayKindGRPC && node.Available {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		for url := range rpcClientsMap {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// recordGatewayResult record result of request to gateway node
+func recordGatewayResult(url string, err error, latency time.Duration) {
+	t := gatewayHealth
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	node, exist := t.nodes[url]
+	if !exist {
+		return
+	}
+	node.record(err, latency)
+	if node.Available && node.ConsecutiveFailures >= GatewayFailureThreshold {
+		t.open(node, "too many failures")
+	}
+}
+
+// recordGatewayQuery record result of query to gateway node.
+// errors responded by the node (eg. tx not found, simulation failed) are not failures of the node,
+// and canceled queries (eg. the http request is done) are not recorded.
+func recordGatewayQuery(url string, err error, latency time.Duration) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if !isGatewayFailure(err) {
+		err = nil
+	}
+	recordGatewayResult(url, err, latency)
+}
+
+// isGatewayFailure the query error is caused by the gateway node (eg. timeout, connection refused)
+func isGatewayFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr *responseStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var rpcErr *rpctypes.RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	return !errors.As(err, &grpcErr)
+}
+
+func (node *GatewayStatus) record(err error, latency time.Duration) {
+	metrics.ObserveGatewayQuery(node.Label, node.Kind, err, latency)
+	node.Requests++
+	node.LastCheck = time.Now()
+	failed := 0.0
+	if err != nil {
+		failed = 1
+		node.Failures++
+		node.ConsecutiveFailures++
+		node.SuccessfulProbes = 0
+		node.LastError = err.Error()
+	} else {
+		node.ConsecutiveFailures = 0
+		if node.Latency == 0 {
+			node.Latency = latency
+		} else {
+			node.Latency = (node.Latency*4 + latency) / 5
+		}
+	}
+	node.ErrorRate = node.ErrorRate*0.8 + failed*0.2
+}
+
+func (t *gatewayHealthTracker) open(node *GatewayStatus, reason string) {
+	node.Available = false
+	node.OpenedAt = time.Now()
+	node.SuccessfulProbes = 0
+	log.Warn("remove unhealthy gateway", "url", node.URL, "kind", node.Kind, "reason", reason,
+		"height", node.Height, "lag", node.Lag, "errorRate", node.ErrorRate, "lastError", node.LastError)
+	t.updateActive()
+}
+
+// updateActive rebuild available node lists, ordered by lag and latency
+func (t *gatewayHealthTracker) updateActive() {
+	var grpcNodes, restNodes []*GatewayStatus
+	for _, url := range t.order {
+		node := t.nodes[url]
+		if !node.Available {
+			continue
+		}
+		if node.Kind == GatewayKindGRPC {
+			grpcNodes = append(grpcNodes, node)
+		} else {
+			restNodes = append(restNodes, node)
+		}
+	}
+	sortNodes(grpcNodes)
+	sortNodes(restNodes)
+
+	t.activeGRPC = make([]*grpcClient, 0, len(grpcNodes))
+	for _, node := range grpcNodes {
+		t.activeGRPC = append(t.activeGRPC, &grpcClient{URL: node.URL, Client: rpcClientsMap[node.URL]})
+	}
+	t.activeREST = make([]string, 0, len(restNodes))
+	for _, node := range restNodes {
+		t.activeREST = append(t.activeREST, node.URL)
+	}
+}
+
+func sortNodes(nodes []*GatewayStatus) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Lag != nodes[j].Lag {
+			return nodes[i].Lag < nodes[j].Lag
+		}
+		return nodes[i].Latency < nodes[j].Latency
+	})
+}
+
+// ProbeGateways query latest block number of every node,
+// opens the circuit of failing or lagging nodes and re-admits recovered nodes.
+func (b *Bridge) ProbeGateways() {
+	gatewayHealth.lock.RLock()
+	nodes := make([]GatewayStatus, 0, len(gatewayHealth.order))
+	for _, url := range gatewayHealth.order {
+		nodes = append(nodes, *gatewayHealth.nodes[url])
+	}
+	gatewayHealth.lock.RUnlock()
+
+	type probeResult struct {
+		height  uint64
+		err     error
+		latency time.Duration
+	}
+	results := make([]probeResult, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node GatewayStatus) {
+			defer wg.Done()
+			start := time.Now()
+			var height uint64
+			var err error
+			if node.Kind == GatewayKindGRPC {
+				height, err = b.GRPCGetLatestBlockNumberOf(baseCtx, node.URL)
+			} else {
+				height, err = b.GetLatestBlockNumberOfWithContext(baseCtx, node.URL)
+			}
+			results[i] = probeResult{height: height, err: err, latency: time.Since(start)}
+		}(i, node)
+	}
+	wg.Wait()
+
+	var maxHeight uint64
+	for _, result := range results {
+		if result.err == nil && result.height > maxHeight {
+			maxHeight = result.height
+		}
+	}
+
+	t := gatewayHealth
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for i, result := range results {
+		node, exist := t.nodes[nodes[i].URL]
+		if !exist {
+			continue
+		}
+		node.record(result.err, result.latency)
+		if result.err == nil {
+			node.Height = result.height
+			node.Lag = maxHeight - result.height
+		}
+		healthy := result.err == nil && node.Lag <= GatewayMaxLagBlocks
+		switch {
+		case node.Available && node.ConsecutiveFailures >= GatewayFailureThreshold:
+			t.open(node, "too many failures")
+		case node.Available && result.err == nil && !healthy:
+			t.open(node, "lag behind")
+		case !node.Available && healthy:
+			node.SuccessfulProbes++
+			if node.SuccessfulProbes >= GatewayReadmitProbes && time.Since(node.OpenedAt) >= GatewayOpenDuration {
+				node.Available = true
+				node.OpenedAt = time.Time{}
+				node.SuccessfulProbes = 0
+				log.Info("re-admit recovered gateway", "url", node.URL, "kind", node.Kind, "height", node.Height)
+			}
+		case !node.Available:
+			node.SuccessfulProbes = 0
+		}
+	}
+	t.updateActive()
+	if maxHeight > 0 {
+		observeLatestHeight(maxHeight)
+		log.Debug("probe gateways finished", "maxHeight", maxHeight, "grpc", len(t.activeGRPC), "rest", len(t.activeREST))
+	}
+}
+
+// GetGatewayStatus get health status of all gateway nodes
+func GetGatewayStatus() []*GatewayStatus {
+	gatewayHealth.lock.RLock()
+	defer gatewayHealth.lock.RUnlock()
+	result := make([]*GatewayStatus, 0, len(gatewayHealth.order))
+	for _, url := range gatewayHealth.order {
+		status := *gatewayHealth.nodes[url]
+		result = append(result, &status)
+	}
+	return result
+}
@@ -4,25 +4,31 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/log"
 	"github.com/anyswap/RouterSDK-injective/grpc"
 	cosmosclient "github.com/cosmos/cosmos-sdk/client"
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
 	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 )
 
 var (
-	rpcClients    []rpcclient.Client
+	rpcClients    []*grpcClient
 	rpcClientsMap = make(map[string]rpcclient.Client)
-
-	ctx = context.Background()
 )
 
+// grpcClient rpc client of the node of url
+type grpcClient struct {
+	URL string
+	rpcclient.Client
+}
+
 func (b *Bridge) initGrpcClients() {
 	for _, url := range b.GatewayConfig.GRPCAPIAddress {
 		rpcClient, err := cosmosclient.NewClientFromNode(url)
@@ -30,7 +36,7 @@ func (b *Bridge) initGrpcClients() {
 			log.Warn("new grpc client failed", "url", url, "err", err)
 			continue
 		}
-		rpcClients = append(rpcClients, rpcClient)
+		rpcClients = append(rpcClients, &grpcClient{URL: url, Client: rpcClient})
 		rpcClientsMap[url] = rpcClient
 	}
 	if len(rpcClients) > 0 {
@@ -38,10 +44,14 @@ func (b *Bridge) initGrpcClients() {
 	}
 }
 
-func (b *Bridge) GRPCGetLatestBlockNumber() (res uint64, err error) {
-	for _, rpcClient := range rpcClients {
+func (b *Bridge) GRPCGetLatestBlockNumber(ctx context.Context) (res uint64, err error) {
+	for _, rpcClient := range grpcClients() {
 		clientCtx := b.ClientContext.WithClient(rpcClient)
-		res, err = grpc.GetLatestBlockNumber(ctx, clientCtx)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetLatestBlockNumber")
+		start := time.Now()
+		res, err = grpc.GetLatestBlockNumber(queryCtx, clientCtx)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return res, nil
 		}
@@ -52,7 +62,7 @@ func (b *Bridge) GRPCGetLatestBlockNumber() (res uint64, err error) {
 	return 0, wrapRPCQueryError(err, "GRPCGetLatestBlockNumber")
 }
 
-func (b *Bridge) GRPCGetLatestBlockNumberOf(url string) (res uint64, err error) {
+func (b *Bridge) GRPCGetLatestBlockNumberOf(ctx context.Context, url string) (res uint64, err error) {
 	rpcClient, exist := rpcClientsMap[url]
 	if !exist {
 		rpcClient, err = cosmosclient.NewClientFromNode(url)
@@ -62,7 +72,9 @@ func (b *Bridge) GRPCGetLatestBlockNumberOf(url string) (res uint64, err error)
 		}
 	}
 	clientCtx := b.ClientContext.WithClient(rpcClient)
-	res, err = grpc.GetLatestBlockNumber(ctx, clientCtx)
+	queryCtx, cancel := withQueryTimeout(ctx, "GetLatestBlockNumber")
+	res, err = grpc.GetLatestBlockNumber(queryCtx, clientCtx)
+	cancel()
 	if err == nil {
 		return res, nil
 	}
@@ -72,10 +84,14 @@ func (b *Bridge) GRPCGetLatestBlockNumberOf(url string) (res uint64, err error)
 	return 0, wrapRPCQueryError(err, "GRPCGetLatestBlockNumber")
 }
 
-func (b *Bridge) GRPCGetChainID() (res string, err error) {
-	for _, rpcClient := range rpcClients {
+func (b *Bridge) GRPCGetChainID(ctx context.Context) (res string, err error) {
+	for _, rpcClient := range grpcClients() {
 		clientCtx := b.ClientContext.WithClient(rpcClient)
-		res, err = grpc.GetChainID(ctx, clientCtx)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetChainID")
+		start := time.Now()
+		res, err = grpc.GetChainID(queryCtx, clientCtx)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return res, nil
 		}
@@ -86,37 +102,13 @@ func (b *Bridge) GRPCGetChainID() (res string, err error) {
 	return "", wrapRPCQueryError(err, "GRPCGetChainID")
 }
 
-func (b *Bridge) GRPCGetTransactionByHash(txHash string) (res *GetTxResponse, err error) {
-	var txres *sdk.TxResponse
-	for _, rpcClient := range rpcClients {
-		clientCtx := b.ClientContext.WithClient(rpcClient)
-		txres, err = grpc.GetTransactionByHash(ctx, clientCtx, txHash)
+func (b *Bridge) GRPCGetTransactionByHash(ctx context.Context, txHash string) (res *GetTxResponse, err error) {
+	for _, rpcClient := range grpcClients() {
+		start := time.Now()
+		res, err = b.grpcGetTransactionByHashWith(ctx, rpcClient, txHash)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
 		if err == nil {
-			var tx *sdktx.Tx
-			if err := clientCtx.InterfaceRegistry.UnpackAny(txres.Tx, &tx); err != nil {
-				log.Warn("GRPCGetTransactionByHash failed", "txHash", txHash, "err", err)
-				return nil, errors.WithStack(err)
-			}
-			if tx == nil {
-				return nil, fmt.Errorf("unpack tx error")
-			}
-			var txMemo string
-			if tx.Body != nil {
-				txMemo = tx.Body.Memo
-			}
-			return &GetTxResponse{
-				Tx: &Tx{
-					Body: TxBody{
-						Memo: txMemo,
-					},
-				},
-				TxResponse: &TxResponse{
-					Height: fmt.Sprintf("%v", txres.Height),
-					TxHash: txres.TxHash,
-					Code:   txres.Code,
-					Logs:   txres.Logs,
-				},
-			}, nil
+			return res, nil
 		}
 	}
 	if err != nil {
@@ -125,11 +117,96 @@ func (b *Bridge) GRPCGetTransactionByHash(txHash string) (res *GetTxResponse, er
 	return nil, wrapRPCQueryError(err, "GRPCGetTransactionByHash", txHash)
 }
 
-func (b *Bridge) GRPCGetBaseAccount(address string) (res *QueryAccountResponse, err error) {
+// GRPCGetTransactionByHashOf get tx from the grpc node of url
+func (b *Bridge) GRPCGetTransactionByHashOf(ctx context.Context, url, txHash string) (*GetTxResponse, error) {
+	rpcClient, exist := rpcClientsMap[url]
+	if !exist {
+		return nil, wrapRPCQueryError(fmt.Errorf("grpc client of %v not found", url), "GRPCGetTransactionByHash", txHash)
+	}
+	res, err := b.grpcGetTransactionByHashWith(ctx, rpcClient, txHash)
+	if err != nil {
+		return nil, wrapRPCQueryError(err, "GRPCGetTransactionByHash", txHash)
+	}
+	return res, nil
+}
+
+func (b *Bridge) grpcGetTransactionByHashWith(ctx context.Context, rpcClient rpcclient.Client, txHash string) (*GetTxResponse, error) {
+	clientCtx := b.ClientContext.WithClient(rpcClient)
+	queryCtx, cancel := withQueryTimeout(ctx, "GetTransactionByHash")
+	txres, err := grpc.GetTransactionByHash(queryCtx, clientCtx, txHash)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	var tx *sdktx.Tx
+	if err := clientCtx.InterfaceRegistry.UnpackAny(txres.Tx, &tx); err != nil {
+		log.Warn("GRPCGetTransactionByHash failed", "txHash", txHash, "err", err)
+		return nil, errors.WithStack(err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("unpack tx error")
+	}
+	var txMemo string
+	if tx.Body != nil {
+		txMemo = tx.Body.Memo
+	}
+	return &GetTxResponse{
+		Tx: &Tx{
+			Body: TxBody{
+				Memo: txMemo,
+			},
+		},
+		TxResponse: &TxResponse{
+			Height:    fmt.Sprintf("%v", txres.Height),
+			TxHash:    txres.TxHash,
+			Codespace: txres.Codespace,
+			Code:      txres.Code,
+			RawLog:    txres.RawLog,
+			GasWanted: fmt.Sprintf("%v", txres.GasWanted),
+			GasUsed:   fmt.Sprintf("%v", txres.GasUsed),
+			Logs:      txres.Logs,
+			Events:    convertEvents(txres.Events),
+		},
+	}, nil
+}
+
+func (b *Bridge) GRPCGetBlockTxs(ctx context.Context, height uint64) (res []*BlockTx, err error) {
+	for _, rpcClient := range grpcClients() {
+		clientCtx := b.ClientContext.WithClient(rpcClient)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetBlockTxs")
+		start := time.Now()
+		block, results, errt := grpc.GetBlockResults(queryCtx, clientCtx, int64(height))
+		recordGatewayQuery(rpcClient.URL, errt, time.Since(start))
+		cancel()
+		if errt != nil {
+			err = errt
+			continue
+		}
+		res = make([]*BlockTx, 0, len(results.TxsResults))
+		for i, txResult := range results.TxsResults {
+			res = append(res, &BlockTx{
+				Hash:   fmt.Sprintf("%X", block.Block.Data.Txs[i].Hash()),
+				Code:   txResult.Code,
+				Events: convertEvents(txResult.Events),
+			})
+		}
+		return res, nil
+	}
+	if err != nil {
+		log.Warn("GRPCGetBlockTxs failed", "height", height, "err", err)
+	}
+	return nil, wrapRPCQueryError(err, "GRPCGetBlockTxs", height)
+}
+
+func (b *Bridge) GRPCGetBaseAccount(ctx context.Context, address string) (res *QueryAccountResponse, err error) {
 	var ret authtypes.AccountI
-	for _, rpcClient := range rpcClients {
+	for _, rpcClient := range grpcClients() {
 		clientCtx := b.ClientContext.WithClient(rpcClient)
-		ret, err = grpc.GetAccountInfo(ctx, clientCtx, address)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetBaseAccount")
+		start := time.Now()
+		ret, err = grpc.GetAccountInfo(queryCtx, clientCtx, address)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return &QueryAccountResponse{
 				Account: &BaseAccount{
@@ -146,10 +223,14 @@ func (b *Bridge) GRPCGetBaseAccount(address string) (res *QueryAccountResponse,
 	return nil, wrapRPCQueryError(err, "GRPCGetBaseAccount", address)
 }
 
-func (b *Bridge) GRPCGetDenomBalance(address, denom string) (res sdk.Int, err error) {
-	for _, rpcClient := range rpcClients {
+func (b *Bridge) GRPCGetDenomBalance(ctx context.Context, address, denom string) (res sdk.Int, err error) {
+	for _, rpcClient := range grpcClients() {
 		clientCtx := b.ClientContext.WithClient(rpcClient)
-		res, err = grpc.GetDenomBalance(ctx, clientCtx, address, denom)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetDenomBalance")
+		start := time.Now()
+		res, err = grpc.GetDenomBalance(queryCtx, clientCtx, address, denom)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return res, nil
 		}
@@ -160,36 +241,96 @@ func (b *Bridge) GRPCGetDenomBalance(address, denom string) (res sdk.Int, err er
 	return sdk.ZeroInt(), wrapRPCQueryError(err, "GRPCGetDenomBalance", address, denom)
 }
 
-func (b *Bridge) GRPCSimulateTx(simulateReq *SimulateRequest) (res *sdktx.SimulateResponse, err error) {
-	for _, rpcClient := range rpcClients {
+func (b *Bridge) GRPCGetMinGasPrices(ctx context.Context) (res string, err error) {
+	for _, rpcClient := range grpcClients() {
 		clientCtx := b.ClientContext.WithClient(rpcClient)
-		res, err = grpc.SimulateTx(ctx, clientCtx, []byte(simulateReq.TxBytes))
+		queryCtx, cancel := withQueryTimeout(ctx, "GetMinGasPrices")
+		start := time.Now()
+		res, err = grpc.GetMinGasPrices(queryCtx, clientCtx)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return res, nil
 		}
 	}
 	if err != nil {
-		log.Warn("GRPCSimulateTx failed", "err", err)
+		log.Warn("GRPCGetMinGasPrices failed", "err", err)
 	}
-	return nil, wrapRPCQueryError(err, "GRPCSimulateTx")
+	return "", wrapRPCQueryError(err, "GRPCGetMinGasPrices")
 }
 
-func (b *Bridge) GRPCBroadcastTx(req *BroadcastTxRequest) (res *sdk.TxResponse, err error) {
-	txBytes, err := base64.StdEncoding.DecodeString(req.TxBytes)
+func (b *Bridge) GRPCGetChannelClientLatestHeight(ctx context.Context, portID, channelID string) (res clienttypes.Height, err error) {
+	for _, rpcClient := range grpcClients() {
+		clientCtx := b.ClientContext.WithClient(rpcClient)
+		queryCtx, cancel := withQueryTimeout(ctx, "GetChannelClientLatestHeight")
+		start := time.Now()
+		res, err = grpc.GetChannelClientLatestHeight(queryCtx, clientCtx, portID, channelID)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
+		if err == nil {
+			return res, nil
+		}
+	}
 	if err != nil {
-		return nil, wrapRPCQueryError(err, "GRPCBroadcastTx")
+		log.Warn("GRPCGetChannelClientLatestHeight failed", "port", portID, "channel", channelID, "err", err)
+	}
+	return clienttypes.ZeroHeight(), wrapRPCQueryError(err, "GRPCGetChannelClientLatestHeight", portID, channelID)
+}
+
+func (b *Bridge) GRPCSimulateTx(ctx context.Context, simulateReq *SimulateRequest) (res *sdktx.SimulateResponse, err error) {
+	txBytes, err := base64.StdEncoding.DecodeString(simulateReq.TxBytes)
+	if err != nil {
+		return nil, wrapRPCQueryError(err, "GRPCSimulateTx")
 	}
-	for _, rpcClient := range rpcClients {
-		clientCtx := b.ClientContext.
-			WithClient(rpcClient).
-			WithBroadcastMode(flags.BroadcastSync)
-		res, err = clientCtx.BroadcastTx(txBytes)
+	for _, rpcClient := range grpcClients() {
+		clientCtx := b.ClientContext.WithClient(rpcClient)
+		queryCtx, cancel := withQueryTimeout(ctx, "SimulateTx")
+		start := time.Now()
+		res, err = grpc.SimulateTx(queryCtx, clientCtx, txBytes)
+		recordGatewayQuery(rpcClient.URL, err, time.Since(start))
+		cancel()
 		if err == nil {
 			return res, nil
 		}
 	}
 	if err != nil {
-		log.Warn("GRPCBroadcastTx failed", "err", err)
+		log.Warn("GRPCSimulateTx failed", "err", err)
+	}
+	return nil, wrapRPCQueryError(err, "GRPCSimulateTx")
+}
+
+// GRPCBroadcastTxOf broadcast tx (sync mode) to the grpc node of url
+func (b *Bridge) GRPCBroadcastTxOf(ctx context.Context, url string, txBytes []byte) (*TxResponse, error) {
+	rpcClient, exist := rpcClientsMap[url]
+	if !exist {
+		return nil, wrapRPCQueryError(fmt.Errorf("grpc client of %v not found", url), "GRPCBroadcastTx")
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, "BroadcastTx")
+	defer cancel()
+	res, err := rpcClient.BroadcastTxSync(queryCtx, txBytes)
+	if err != nil {
+		return nil, wrapRPCQueryError(err, "GRPCBroadcastTx")
+	}
+	return &TxResponse{
+		TxHash:    res.Hash.String(),
+		Codespace: res.Codespace,
+		Code:      res.Code,
+		RawLog:    res.Log,
+	}, nil
+}
+
+func convertEvents(events []abci.Event) []Event {
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		attrs := make([]EventAttribute, 0, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attrs = append(attrs, EventAttribute{
+				Key:   string(attr.Key),
+				Value: string(attr.Value),
+				Index: attr.Index,
+			})
+		}
+		result = append(result, Event{Type: event.Type, Attributes: attrs})
 	}
-	return nil, wrapRPCQueryError(err, "GRPCBroadcastTx")
+	return result
 }
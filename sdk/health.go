@@ -0,0 +1,158 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyswap/RouterSDK-injective/config"
+	"github.com/anyswap/RouterSDK-injective/metrics"
+)
+
+var (
+	// HeadStaleDuration the chain head is not advancing if no new height in this duration
+	HeadStaleDuration = 2 * time.Minute
+
+	health = &healthTracker{
+		routerInfos: make(map[string]error),
+	}
+)
+
+// HealthCheck result of one health check
+type HealthCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthReport results of health checks
+type HealthReport struct {
+	OK     bool           `json:"ok"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+type healthTracker struct {
+	lock sync.RWMutex
+
+	routerInfos map[string]error // router contract to init result
+
+	headHeight uint64
+	headTime   time.Time // time of head height advanced
+}
+
+// observeLatestHeight observe latest block height of chain (for metrics and readiness)
+func observeLatestHeight(height uint64) {
+	metrics.ObserveLatestHeight(height)
+
+	health.lock.Lock()
+	defer health.lock.Unlock()
+	if height > health.headHeight {
+		health.headHeight = height
+		health.headTime = time.Now()
+	}
+}
+
+func setRouterInfoStatus(routerContract string, err error) {
+	health.lock.Lock()
+	defer health.lock.Unlock()
+	health.routerInfos[routerContract] = err
+}
+
+// CheckLiveness the process is alive (config is loaded)
+func CheckLiveness() *HealthReport {
+	return newHealthReport(checkConfigLoaded())
+}
+
+// CheckReadiness the service is ready to serve router requests
+func CheckReadiness() *HealthReport {
+	return newHealthReport(
+		checkConfigLoaded(),
+		checkBridgeInited(),
+		checkRouterInfos(),
+		checkGateways(),
+		checkChainHead(),
+	)
+}
+
+func newHealthReport(checks ...*HealthCheck) *HealthReport {
+	report := &HealthReport{OK: true, Checks: checks}
+	for _, check := range checks {
+		if !check.OK {
+			report.OK = false
+		}
+	}
+	return report
+}
+
+func checkConfigLoaded() *HealthCheck {
+	check := &HealthCheck{Name: "config", OK: config.IsLoaded()}
+	if !check.OK {
+		check.Message = "config is not loaded"
+	}
+	return check
+}
+
+func checkBridgeInited() *HealthCheck {
+	check := &HealthCheck{Name: "init", OK: BridgeInited}
+	if !check.OK {
+		check.Message = "init after load is not finished"
+	}
+	return check
+}
+
+// checkRouterInfos router info and mpc public key of all router contracts are verified
+func checkRouterInfos() *HealthCheck {
+	check := &HealthCheck{Name: "routerInfo"}
+	health.lock.RLock()
+	defer health.lock.RUnlock()
+	if len(health.routerInfos) == 0 {
+		check.Message = "no router info is inited"
+		return check
+	}
+	contracts := make([]string, 0, len(health.routerInfos))
+	for contract := range health.routerInfos {
+		contracts = append(contracts, contract)
+	}
+	sort.Strings(contracts)
+	for _, contract := range contracts {
+		if err := health.routerInfos[contract]; err != nil {
+			check.Message = fmt.Sprintf("init router info of %v failed: %v", contract, err)
+			return check
+		}
+	}
+	check.OK = true
+	return check
+}
+
+// checkGateways any gateway is available and its latest query succeeded
+func checkGateways() *HealthCheck {
+	check := &HealthCheck{Name: "gateways"}
+	var available int
+	for _, status := range GetGatewayStatus() {
+		if status.Available && status.ConsecutiveFailures == 0 && !status.LastCheck.IsZero() {
+			available++
+		}
+	}
+	check.OK = available > 0
+	check.Message = fmt.Sprintf("%v reachable gateways", available)
+	return check
+}
+
+// checkChainHead the latest height is advancing
+func checkChainHead() *HealthCheck {
+	check := &HealthCheck{Name: "chainHead"}
+	health.lock.RLock()
+	height, advanced := health.headHeight, health.headTime
+	health.lock.RUnlock()
+	switch {
+	case height == 0:
+		check.Message = "no latest height observed"
+	case time.Since(advanced) > HeadStaleDuration:
+		check.Message = fmt.Sprintf("height %v is not advanced since %v", height, advanced.Format(time.RFC3339))
+	default:
+		check.OK = true
+		check.Message = fmt.Sprintf("height %v", height)
+	}
+	return check
+}
@@ -0,0 +1,230 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+)
+
+var (
+	// DefaultIBCTimeoutSeconds timeout of ibc transfer if no timeout is configured
+	DefaultIBCTimeoutSeconds uint64 = 600
+
+	// token address => ibc routes
+	ibcRoutesMap  = make(map[string][]*IBCRoute)
+	ibcRoutesLock sync.RWMutex
+
+	errNoIBCRoute = fmt.Errorf("no ibc route for receiver")
+)
+
+// TokenConfigExtra token config extra (json format)
+type TokenConfigExtra struct {
+	IBCRoutes []*IBCRoute `json:"ibcRoutes,omitempty"`
+}
+
+// IBCRoute deliver swaps to receivers of `Prefix` by ibc transfer.
+// timeout height is the counterparty client latest height plus `TimeoutHeightOffset`,
+// timeout timestamp is now plus `TimeoutSeconds`.
+type IBCRoute struct {
+	Prefix              string `json:"prefix"`
+	SourcePort          string `json:"sourcePort,omitempty"`
+	SourceChannel       string `json:"sourceChannel"`
+	TimeoutHeightOffset uint64 `json:"timeoutHeightOffset,omitempty"`
+	TimeoutSeconds      uint64 `json:"timeoutSeconds,omitempty"`
+	Memo                string `json:"memo,omitempty"`
+}
+
+// ParseTokenConfigExtra parse token config extra, empty extra is allowed
+func ParseTokenConfigExtra(extra string) (*TokenConfigExtra, error) {
+	cfgExtra := &TokenConfigExtra{}
+	if strings.TrimSpace(extra) == "" {
+		return cfgExtra, nil
+	}
+	if err := json.Unmarshal([]byte(extra), cfgExtra); err != nil {
+		return nil, err
+	}
+	for _, route := range cfgExtra.IBCRoutes {
+		if err := route.CheckConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return cfgExtra, nil
+}
+
+// CheckConfig check and set default values of ibc route
+func (r *IBCRoute) CheckConfig() error {
+	if r.Prefix == "" {
+		return fmt.Errorf("ibc route without prefix")
+	}
+	if r.SourcePort == "" {
+		r.SourcePort = transfertypes.PortID
+	}
+	if !channeltypes.IsValidChannelID(r.SourceChannel) {
+		return fmt.Errorf("ibc route %v has wrong source channel '%v'", r.Prefix, r.SourceChannel)
+	}
+	if r.TimeoutHeightOffset == 0 && r.TimeoutSeconds == 0 {
+		r.TimeoutSeconds = DefaultIBCTimeoutSeconds
+	}
+	return nil
+}
+
+func setIBCRoutes(tokenAddr string, routes []*IBCRoute) {
+	ibcRoutesLock.Lock()
+	defer ibcRoutesLock.Unlock()
+	if len(routes) == 0 {
+		delete(ibcRoutesMap, tokenAddr)
+		return
+	}
+	ibcRoutesMap[tokenAddr] = routes
+}
+
+// GetIBCRoute get ibc route of token to the receiver prefix
+func GetIBCRoute(tokenAddr, prefix string) *IBCRoute {
+	ibcRoutesLock.RLock()
+	defer ibcRoutesLock.RUnlock()
+	for _, route := range ibcRoutesMap[tokenAddr] {
+		if route.Prefix == prefix {
+			return route
+		}
+	}
+	return nil
+}
+
+// IsIBCPrefix is prefix of any token's ibc route
+func IsIBCPrefix(prefix string) bool {
+	ibcRoutesLock.RLock()
+	defer ibcRoutesLock.RUnlock()
+	for _, routes := range ibcRoutesMap {
+		for _, route := range routes {
+			if route.Prefix == prefix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetAddressPrefix get bech32 prefix of address
+func GetAddressPrefix(address string) string {
+	prefix, _, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return ""
+	}
+	return prefix
+}
+
+// IsValidIBCReceiver is valid address of ibc counterparty chain routed for the token
+func IsValidIBCReceiver(tokenAddr, address string) bool {
+	prefix := GetAddressPrefix(address)
+	return prefix != "" && GetIBCRoute(tokenAddr, prefix) != nil && IsValidAddress(prefix, address)
+}
+
+// isValidBindAddress check bind address of memo in dest chain,
+// the bind may be receiver of any token's ibc route if dest chain is this chain,
+// it is checked against the routes of the swapped token in `checkSwapoutInfo`.
+func isValidBindAddress(dstBridge tokens.IBridge, bind string) bool {
+	if dstBridge.IsValidAddress(bind) {
+		return true
+	}
+	if _, ok := dstBridge.(*Bridge); !ok {
+		return false
+	}
+	prefix := GetAddressPrefix(bind)
+	return prefix != "" && IsIBCPrefix(prefix) && IsValidAddress(prefix, bind)
+}
+
+// BuildTransferMsg build ibc transfer msg
+func BuildTransferMsg(
+	sourcePort, sourceChannel, from, to, denom string,
+	amount *big.Int,
+	timeoutHeight clienttypes.Height,
+	timeoutTimestamp uint64,
+	memo string,
+) *transfertypes.MsgTransfer {
+	msg := transfertypes.NewMsgTransfer(
+		sourcePort, sourceChannel,
+		sdk.NewCoin(denom, sdk.NewIntFromBigInt(amount)),
+		from, to,
+		timeoutHeight, timeoutTimestamp,
+	)
+	msg.Memo = memo
+	return msg
+}
+
+// buildDeliverMsg build msg which delivers swap amount to receiver,
+// use ibc transfer if receiver is on ibc counterparty chain.
+func (b *Bridge) buildDeliverMsg(ctx context.Context, args *tokens.BuildTxArgs, from, to, denom string, amount *big.Int) (sdk.Msg, error) {
+	prefix := GetAddressPrefix(to)
+	if prefix == b.Prefix {
+		return BuildSendMsg(from, to, denom, amount), nil
+	}
+	route := GetIBCRoute(denom, prefix)
+	if route == nil {
+		log.Warn("no ibc route for receiver", "swapID", args.SwapID, "denom", denom, "receiver", to)
+		return nil, errNoIBCRoute
+	}
+	timeoutHeight := clienttypes.ZeroHeight()
+	if route.TimeoutHeightOffset > 0 {
+		latestHeight, err := b.GetChannelClientLatestHeight(ctx, route.SourcePort, route.SourceChannel)
+		if err != nil {
+			return nil, err
+		}
+		timeoutHeight = clienttypes.NewHeight(latestHeight.RevisionNumber, latestHeight.RevisionHeight+route.TimeoutHeightOffset)
+	}
+	var timeoutTimestamp uint64
+	if route.TimeoutSeconds > 0 {
+		timeoutTimestamp = uint64(time.Now().Add(time.Duration(route.TimeoutSeconds) * time.Second).UnixNano())
+	}
+	log.Info("build ibc transfer msg", "swapID", args.SwapID, "receiver", to, "denom", denom, "amount", amount,
+		"port", route.SourcePort, "channel", route.SourceChannel, "timeoutHeight", timeoutHeight, "timeoutTimestamp", timeoutTimestamp)
+	return BuildTransferMsg(route.SourcePort, route.SourceChannel, from, to, denom, amount, timeoutHeight, timeoutTimestamp, route.Memo), nil
+}
+
+// getIBCTransferAmount get total amount of denom sent from sender to receiver by ibc transfer in message log
+func getIBCTransferAmount(messageLog sdk.ABCIMessageLog, sender, receiver, denom string) *big.Int {
+	total := big.NewInt(0)
+	for _, event := range messageLog.Events {
+		if event.Type != channeltypes.EventTypeSendPacket {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key != channeltypes.AttributeKeyData {
+				continue
+			}
+			var data transfertypes.FungibleTokenPacketData
+			if err := json.Unmarshal([]byte(attr.Value), &data); err != nil {
+				continue
+			}
+			if data.Sender != sender || data.Receiver != receiver || !isPacketDenom(data.Denom, denom) {
+				continue
+			}
+			if amount, ok := new(big.Int).SetString(data.Amount, 10); ok {
+				total.Add(total, amount)
+			}
+		}
+	}
+	return total
+}
+
+// isPacketDenom is packet denom (full denom trace path) the same as local denom
+func isPacketDenom(packetDenom, denom string) bool {
+	if packetDenom == denom {
+		return true
+	}
+	if strings.HasPrefix(denom, transfertypes.DenomPrefix+"/") {
+		return transfertypes.ParseDenomTrace(packetDenom).IBCDenom() == denom
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package sdk
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+)
+
+// IBCDeposit ics20 transfer received by `MsgRecvPacket`
+type IBCDeposit struct {
+	Sender   string
+	Receiver string
+	Denom    string // denom on this chain (eg. `ibc/{hash}`)
+	Amount   *big.Int
+	Memo     string
+}
+
+// ParseIBCDeposit parse ics20 transfer received in message log,
+// returns nil if the message is not a received ics20 packet.
+func ParseIBCDeposit(messageLog sdk.ABCIMessageLog) (*IBCDeposit, error) {
+	var packetData, srcPort, srcChannel, dstPort, dstChannel, ackSuccess string
+	var isTransferPacket bool
+	for _, event := range messageLog.Events {
+		switch event.Type {
+		case channeltypes.EventTypeRecvPacket:
+			for _, attr := range event.Attributes {
+				switch attr.Key {
+				case channeltypes.AttributeKeyData:
+					packetData = attr.Value
+				case channeltypes.AttributeKeySrcPort:
+					srcPort = attr.Value
+				case channeltypes.AttributeKeySrcChannel:
+					srcChannel = attr.Value
+				case channeltypes.AttributeKeyDstPort:
+					dstPort = attr.Value
+				case channeltypes.AttributeKeyDstChannel:
+					dstChannel = attr.Value
+				}
+			}
+		case transfertypes.EventTypePacket:
+			isTransferPacket = true
+			for _, attr := range event.Attributes {
+				if attr.Key == transfertypes.AttributeKeyAckSuccess {
+					ackSuccess = attr.Value
+				}
+			}
+		}
+	}
+	if packetData == "" || !isTransferPacket {
+		return nil, nil
+	}
+	if ackSuccess != "true" {
+		log.Warn("ibc transfer packet is not received successfully", "srcChannel", srcChannel, "dstChannel", dstChannel)
+		return nil, tokens.ErrTxWithWrongStatus
+	}
+
+	var data transfertypes.FungibleTokenPacketData
+	if err := json.Unmarshal([]byte(packetData), &data); err != nil {
+		log.Warn("unmarshal ibc transfer packet data failed", "data", packetData, "err", err)
+		return nil, tokens.ErrDepositNotFound
+	}
+	amount, ok := new(big.Int).SetString(data.Amount, 10)
+	if !ok {
+		return nil, tokens.ErrTxWithWrongValue
+	}
+	return &IBCDeposit{
+		Sender:   data.Sender,
+		Receiver: data.Receiver,
+		Denom:    GetReceivedIBCDenom(srcPort, srcChannel, dstPort, dstChannel, data.Denom),
+		Amount:   amount,
+		Memo:     data.Memo,
+	}, nil
+}
+
+// GetReceivedIBCDenom get denom on this chain of received ics20 packet denom (same as the transfer keeper)
+func GetReceivedIBCDenom(srcPort, srcChannel, dstPort, dstChannel, packetDenom string) string {
+	if transfertypes.ReceiverChainIsSource(srcPort, srcChannel, packetDenom) {
+		// token returns back to this chain, remove the prefix added by the sender chain
+		voucherPrefix := transfertypes.GetDenomPrefix(srcPort, srcChannel)
+		return transfertypes.ParseDenomTrace(packetDenom[len(voucherPrefix):]).IBCDenom()
+	}
+	prefixedDenom := transfertypes.GetPrefixedDenom(dstPort, dstChannel, packetDenom)
+	return transfertypes.ParseDenomTrace(prefixedDenom).IBCDenom()
+}
+
+// parseSwapInfo parse bind, value and token of swap in message log,
+// returns the json swap memo extra (nil if not exist).
+// deposits by ibc use the ics20 packet memo instead of the tx memo.
+func (b *Bridge) parseSwapInfo(messageLog sdk.ABCIMessageLog, txMemo string, swapInfo *tokens.SwapTxInfo) (*SwapMemoExtra, error) {
+	deposit, err := ParseIBCDeposit(messageLog)
+	if err != nil {
+		return nil, err
+	}
+	if deposit == nil {
+		extra, err := ParseMemo(swapInfo, txMemo)
+		if err != nil {
+			return nil, err
+		}
+		return extra, b.ParseAmountTotal(messageLog, swapInfo)
+	}
+	extra, err := ParseMemo(swapInfo, deposit.Memo)
+	if err != nil {
+		return nil, err
+	}
+	return extra, b.parseIBCDepositAmount(deposit, swapInfo)
+}
+
+func (b *Bridge) parseIBCDepositAmount(deposit *IBCDeposit, swapInfo *tokens.SwapTxInfo) error {
+	tokenCfg := b.GetTokenConfig(deposit.Denom)
+	if tokenCfg == nil {
+		log.Debug("ibc deposit ignore token config", "denom", deposit.Denom)
+		return tokens.ErrMissTokenConfig
+	}
+	mpc := b.GetRouterContract(deposit.Denom)
+	if !common.IsEqualIgnoreCase(deposit.Receiver, mpc) {
+		log.Debug("ibc deposit receiver mismatch", "denom", deposit.Denom, "have", deposit.Receiver, "want", mpc)
+		return tokens.ErrDepositNotFound
+	}
+	if deposit.Amount.Sign() <= 0 {
+		return tokens.ErrDepositNotFound
+	}
+	swapInfo.From = deposit.Sender
+	swapInfo.Value = deposit.Amount
+	swapInfo.ERC20SwapInfo.Token = deposit.Denom
+	swapInfo.ERC20SwapInfo.TokenID = tokenCfg.TokenID
+	return nil
+}
@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+)
+
+func TestGetReceivedIBCDenom(t *testing.T) {
+	tests := []struct {
+		name        string
+		packetDenom string
+		want        string
+	}{
+		{"native denom of sender chain", "uosmo", transfertypes.ParseDenomTrace("transfer/channel-1/uosmo").IBCDenom()},
+		{"voucher of other chain", "transfer/channel-9/uatom", transfertypes.ParseDenomTrace("transfer/channel-1/transfer/channel-9/uatom").IBCDenom()},
+		{"returning native denom", "transfer/channel-5/inj", "inj"},
+		{"returning voucher", "transfer/channel-5/transfer/channel-9/uatom", transfertypes.ParseDenomTrace("transfer/channel-9/uatom").IBCDenom()},
+		{"prefix of other channel", "transfer/channel-6/inj", transfertypes.ParseDenomTrace("transfer/channel-1/transfer/channel-6/inj").IBCDenom()},
+	}
+	for _, test := range tests {
+		denom := GetReceivedIBCDenom("transfer", "channel-5", "transfer", "channel-1", test.packetDenom)
+		if denom != test.want {
+			t.Errorf("%v: received denom mismatch, have %v want %v", test.name, denom, test.want)
+		}
+	}
+}
+
+func newTestRecvPacketLog(packetData, ackSuccess string) sdk.ABCIMessageLog {
+	return sdk.ABCIMessageLog{Events: sdk.StringEvents{
+		{Type: channeltypes.EventTypeRecvPacket, Attributes: []sdk.Attribute{
+			{Key: channeltypes.AttributeKeyData, Value: packetData},
+			{Key: channeltypes.AttributeKeySrcPort, Value: "transfer"},
+			{Key: channeltypes.AttributeKeySrcChannel, Value: "channel-5"},
+			{Key: channeltypes.AttributeKeyDstPort, Value: "transfer"},
+			{Key: channeltypes.AttributeKeyDstChannel, Value: "channel-1"},
+		}},
+		{Type: transfertypes.EventTypePacket, Attributes: []sdk.Attribute{
+			{Key: transfertypes.AttributeKeyAckSuccess, Value: ackSuccess},
+		}},
+	}}
+}
+
+func newTestPacketData(t *testing.T, denom, amount string) string {
+	t.Helper()
+	data := transfertypes.NewFungibleTokenPacketData(denom, amount, "osmo1sender", "inj1mpc")
+	data.Memo = `{"v":1,"bind":"0x1111","toChainID":"56"}`
+	bs, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal packet data failed: %v", err)
+	}
+	return string(bs)
+}
+
+func TestParseIBCDeposit(t *testing.T) {
+	voucherDenom := transfertypes.ParseDenomTrace("transfer/channel-1/uosmo").IBCDenom()
+	tests := []struct {
+		name       string
+		messageLog sdk.ABCIMessageLog
+		denom      string
+		err        error
+	}{
+		{"native denom", newTestRecvPacketLog(newTestPacketData(t, "uosmo", "100"), "true"), voucherDenom, nil},
+		{"returning denom", newTestRecvPacketLog(newTestPacketData(t, "transfer/channel-5/inj", "100"), "true"), "inj", nil},
+		{"failed packet", newTestRecvPacketLog(newTestPacketData(t, "uosmo", "100"), "false"), "", tokens.ErrTxWithWrongStatus},
+		{"no ack", newTestRecvPacketLog(newTestPacketData(t, "uosmo", "100"), ""), "", tokens.ErrTxWithWrongStatus},
+		{"not json data", newTestRecvPacketLog("uosmo:100", "true"), "", tokens.ErrDepositNotFound},
+		{"not number amount", newTestRecvPacketLog(newTestPacketData(t, "uosmo", "0x64"), "true"), "", tokens.ErrTxWithWrongValue},
+		{"empty amount", newTestRecvPacketLog(newTestPacketData(t, "uosmo", ""), "true"), "", tokens.ErrTxWithWrongValue},
+	}
+	for _, test := range tests {
+		deposit, err := ParseIBCDeposit(test.messageLog)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%v: parse ibc deposit error mismatch, have %v want %v", test.name, err, test.err)
+			continue
+		}
+		if test.err != nil {
+			if deposit != nil {
+				t.Errorf("%v: parse wrong ibc deposit success: %+v", test.name, deposit)
+			}
+			continue
+		}
+		if deposit == nil {
+			t.Errorf("%v: ibc deposit not found", test.name)
+			continue
+		}
+		if deposit.Denom != test.denom || deposit.Amount.Int64() != 100 ||
+			deposit.Sender != "osmo1sender" || deposit.Receiver != "inj1mpc" || deposit.Memo == "" {
+			t.Errorf("%v: ibc deposit mismatch: %+v", test.name, deposit)
+		}
+	}
+}
+
+func TestParseIBCDepositNotPacket(t *testing.T) {
+	tests := []struct {
+		name       string
+		messageLog sdk.ABCIMessageLog
+	}{
+		{"bank transfer", newTestMessageLog("osmo1sender", newTestTransferEvent("osmo1sender", "inj1mpc", "100inj"))},
+		{"not transfer packet", sdk.ABCIMessageLog{Events: newTestRecvPacketLog(newTestPacketData(t, "uosmo", "100"), "true").Events[:1]}},
+		{"no packet data", sdk.ABCIMessageLog{Events: newTestRecvPacketLog("", "true").Events}},
+	}
+	for _, test := range tests {
+		deposit, err := ParseIBCDeposit(test.messageLog)
+		if deposit != nil || err != nil {
+			t.Errorf("%v: not ics20 packet is parsed as deposit: %+v, err %v", test.name, deposit, err)
+		}
+	}
+}
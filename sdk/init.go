@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"strings"
 
+	cryptoCodec "github.com/InjectiveLabs/sdk-go/chain/crypto/codec"
 	tokenfactoryTypes "github.com/InjectiveLabs/sdk-go/chain/tokenfactory/types"
 	chainTypes "github.com/InjectiveLabs/sdk-go/chain/types"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -17,6 +18,10 @@ import (
 	authTx "github.com/cosmos/cosmos-sdk/x/auth/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transferTypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	clientTypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channelTypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	ibctmTypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
 )
 
 const (
@@ -30,12 +35,17 @@ func NewClientContext() cosmosClient.Context {
 
 	interfaceRegistry := codecTypes.NewInterfaceRegistry()
 	interfaceRegistry.RegisterImplementations((*cryptoTypes.PubKey)(nil), &secp256k1.PubKey{})
+	cryptoCodec.RegisterInterfaces(interfaceRegistry)
 
 	authtypes.RegisterInterfaces(interfaceRegistry)
 	bankTypes.RegisterInterfaces(interfaceRegistry)
 	sdktx.RegisterInterfaces(interfaceRegistry)
 	tokenfactoryTypes.RegisterInterfaces(interfaceRegistry)
 	chainTypes.RegisterInterfaces(interfaceRegistry)
+	transferTypes.RegisterInterfaces(interfaceRegistry)
+	clientTypes.RegisterInterfaces(interfaceRegistry)
+	channelTypes.RegisterInterfaces(interfaceRegistry)
+	ibctmTypes.RegisterInterfaces(interfaceRegistry)
 
 	protoCodec := codec.NewProtoCodec(interfaceRegistry)
 	txConfig := authTx.NewTxConfig(protoCodec, authTx.DefaultSignModes)
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// KeyType account key type, decides pubkey type, address and sign hash
+type KeyType string
+
+const (
+	// Secp256k1KeyType cosmos secp256k1 key, address is RIPEMD160(SHA256(pubkey))
+	Secp256k1KeyType KeyType = "secp256k1"
+	// EthSecp256k1KeyType injective ethsecp256k1 key, address is Keccak256(pubkey)[12:]
+	EthSecp256k1KeyType KeyType = "ethsecp256k1"
+
+	DefaultKeyType = Secp256k1KeyType
+)
+
+// GetKeyType get key type by name, empty name means default key type
+func GetKeyType(name string) (KeyType, error) {
+	switch keyType := KeyType(name); keyType {
+	case "":
+		return DefaultKeyType, nil
+	case Secp256k1KeyType, EthSecp256k1KeyType:
+		return keyType, nil
+	default:
+		return "", fmt.Errorf("unknown key type: %v", name)
+	}
+}
+
+// PubKeyFromBytes get public key of this key type from bytes (may be uncompressed)
+func (t KeyType) PubKeyFromBytes(pubKeyBytes []byte) (cryptoTypes.PubKey, error) {
+	cmp, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case Secp256k1KeyType, "":
+		compressedPublicKey := make([]byte, secp256k1.PubKeySize)
+		copy(compressedPublicKey, cmp.SerializeCompressed())
+		return &secp256k1.PubKey{Key: compressedPublicKey}, nil
+	case EthSecp256k1KeyType:
+		compressedPublicKey := make([]byte, ethsecp256k1.PubKeySize)
+		copy(compressedPublicKey, cmp.SerializeCompressed())
+		return &ethsecp256k1.PubKey{Key: compressedPublicKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown key type: %v", t)
+	}
+}
+
+// PrivKeyFromBytes get private key of this key type from bytes
+func (t KeyType) PrivKeyFromBytes(privKeyBytes []byte) (cryptoTypes.PrivKey, error) {
+	switch t {
+	case Secp256k1KeyType, "":
+		return &secp256k1.PrivKey{Key: privKeyBytes}, nil
+	case EthSecp256k1KeyType:
+		return &ethsecp256k1.PrivKey{Key: privKeyBytes}, nil
+	default:
+		return nil, fmt.Errorf("unknown key type: %v", t)
+	}
+}
+
+// SignHash returns the hash of sign bytes which is signed by mpc
+func (t KeyType) SignHash(signBytes []byte) []byte {
+	if t == EthSecp256k1KeyType {
+		return common.Keccak256Hash(signBytes).Bytes()
+	}
+	return Sha256Sum(signBytes)
+}
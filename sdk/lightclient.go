@@ -0,0 +1,354 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	"github.com/anyswap/RouterSDK-injective/config"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	lighthttp "github.com/tendermint/tendermint/light/provider/http"
+	lightdb "github.com/tendermint/tendermint/light/store/db"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+var (
+	// ErrLightClientVerify the tx is not proved by the header verified by light client
+	ErrLightClientVerify = errors.New("light client verification failed")
+
+	lightClient     *light.Client
+	lightClientLock sync.Mutex
+)
+
+// lightClientConfig get enabled light client config, returns nil if disabled
+func lightClientConfig() *config.LightClientConfig {
+	cfg := config.GetServerConfig().LightClientConfig
+	if cfg == nil || !cfg.Enable {
+		return nil
+	}
+	return cfg
+}
+
+// getLightClient init the light client (bootstrapped from the configed trusted header) on first use
+func (b *Bridge) getLightClient(ctx context.Context) (*light.Client, error) {
+	lightClientLock.Lock()
+	defer lightClientLock.Unlock()
+	if lightClient != nil {
+		return lightClient, nil
+	}
+
+	cfg := lightClientConfig()
+	if cfg == nil {
+		return nil, errors.New("light client is not enabled")
+	}
+	urls := b.GatewayConfig.GRPCAPIAddress
+	if len(urls) == 0 {
+		return nil, errors.New("light client has no tendermint rpc url")
+	}
+	chainID, err := b.GetChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := lighthttp.New(chainID, urls[0])
+	if err != nil {
+		return nil, err
+	}
+	witnessURLs := cfg.Witnesses
+	if len(witnessURLs) == 0 {
+		witnessURLs = urls[1:]
+	}
+	if len(witnessURLs) == 0 {
+		log.Warn("light client has no independent witness, use the primary as witness", "primary", urls[0])
+		witnessURLs = urls[:1]
+	}
+	witnesses := make([]provider.Provider, 0, len(witnessURLs))
+	for _, url := range witnessURLs {
+		witness, errw := lighthttp.New(chainID, url)
+		if errw != nil {
+			return nil, errw
+		}
+		witnesses = append(witnesses, witness)
+	}
+
+	var db dbm.DB
+	if cfg.DBDir == "" {
+		db = dbm.NewMemDB()
+	} else if db, err = dbm.NewGoLevelDB("light-client", cfg.DBDir); err != nil {
+		return nil, err
+	}
+
+	trustOptions := light.TrustOptions{
+		Period: time.Duration(cfg.TrustingPeriodHours) * time.Hour,
+		Height: cfg.TrustedHeight,
+		Hash:   common.FromHex(cfg.TrustedHash),
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, "VerifyLightBlock")
+	defer cancel()
+	lc, err := light.NewClient(queryCtx, chainID, trustOptions, primary, witnesses, lightdb.New(db, chainID))
+	if err != nil {
+		log.Warn("init light client failed", "chainID", chainID, "trustedHeight", cfg.TrustedHeight, "err", err)
+		return nil, err
+	}
+	log.Info("init light client success", "chainID", chainID, "trustedHeight", cfg.TrustedHeight,
+		"primary", urls[0], "witnesses", witnessURLs)
+	lightClient = lc
+	return lightClient, nil
+}
+
+// verifyTxByLightClient verify the block header at tx height by light client (from the trusted headers),
+// verify the tx is included in the block by the merkle proof of the header's `DataHash`,
+// and verify the tx result by the `LastResultsHash` of the next header.
+// the swap message (sender, receiver, amount and memo) is checked against the proved tx bytes.
+func (b *Bridge) verifyTxByLightClient(ctx context.Context, swapInfo *tokens.SwapTxInfo, extra *SwapMemoExtra, txr *GetTxResponse) error {
+	txHash := swapInfo.Hash
+	txHeight, err := strconv.ParseInt(txr.TxResponse.Height, 10, 64)
+	if err != nil {
+		return err
+	}
+	txHashBytes, err := hex.DecodeString(txHash)
+	if err != nil {
+		return err
+	}
+
+	lc, err := b.getLightClient(ctx)
+	if err != nil {
+		return wrapRPCQueryError(err, "getLightClient")
+	}
+	proof, proofHeight, err := b.getTxProof(ctx, txHashBytes)
+	if err != nil {
+		return err
+	}
+	if proofHeight != txHeight {
+		log.Warn("light client tx proof height mismatch", "txHash", txHash, "height", txHeight, "proofHeight", proofHeight)
+		return ErrLightClientVerify
+	}
+
+	lightBlock, err := verifyLightBlock(ctx, lc, txHeight)
+	if err != nil {
+		return err
+	}
+
+	if err = proof.Validate(lightBlock.DataHash); err != nil {
+		log.Warn("light client verify tx proof failed", "txHash", txHash, "height", txHeight, "err", err)
+		return ErrLightClientVerify
+	}
+	if !bytes.Equal(proof.Leaf(), txHashBytes) {
+		log.Warn("light client tx proof hash mismatch", "txHash", txHash, "have", fmt.Sprintf("%X", proof.Leaf()))
+		return ErrLightClientVerify
+	}
+
+	result, err := b.getProvedTxResult(ctx, lc, txHeight, proof)
+	if err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		log.Warn("light client proved tx is failed", "txHash", txHash, "height", txHeight, "code", result.Code)
+		return ErrLightClientVerify
+	}
+
+	tx, err := b.TxConfig.TxDecoder()(proof.Data)
+	if err != nil {
+		return fmt.Errorf("%w: decode tx failed: %v", ErrLightClientVerify, err)
+	}
+	if err = b.verifyProvedMsg(tx, swapInfo, extra, result); err != nil {
+		log.Warn("light client verify swap message failed", "txHash", txHash, "logIndex", swapInfo.LogIndex, "err", err)
+		return err
+	}
+	log.Info("light client verify tx success", "txHash", txHash, "height", txHeight,
+		"blockHash", strings.ToUpper(hex.EncodeToString(lightBlock.Hash())))
+	return nil
+}
+
+// verifyLightBlock verify the block header at height by light client
+func verifyLightBlock(ctx context.Context, lc *light.Client, height int64) (*types.LightBlock, error) {
+	queryCtx, cancel := withQueryTimeout(ctx, "VerifyLightBlock")
+	defer cancel()
+	lightBlock, err := lc.VerifyLightBlockAtHeight(queryCtx, height, time.Now())
+	if err != nil {
+		if errors.Is(err, light.ErrLightClientAttack) {
+			log.Error("light client attack detected", "height", height, "err", err)
+		} else {
+			log.Warn("light client verify header failed", "height", height, "err", err)
+		}
+		return nil, wrapRPCQueryError(err, "VerifyLightBlockAtHeight", height)
+	}
+	return lightBlock, nil
+}
+
+// getProvedTxResult get the deliver tx result of the proved tx,
+// the results of block at height are proved by the `LastResultsHash` of the verified header at height+1.
+func (b *Bridge) getProvedTxResult(ctx context.Context, lc *light.Client, height int64, proof *types.TxProof) (*abci.ResponseDeliverTx, error) {
+	nextBlock, err := verifyLightBlock(ctx, lc, height+1)
+	if err != nil {
+		return nil, err
+	}
+	blockResults, err := b.getBlockResults(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	results := types.NewResults(blockResults.TxsResults)
+	if !bytes.Equal(results.Hash(), nextBlock.LastResultsHash) {
+		log.Warn("light client block results hash mismatch", "height", height,
+			"have", fmt.Sprintf("%X", results.Hash()), "want", nextBlock.LastResultsHash)
+		return nil, ErrLightClientVerify
+	}
+	index := proof.Proof.Index
+	if proof.Proof.Total != int64(len(results)) || index < 0 || index >= int64(len(results)) {
+		log.Warn("light client tx index out of range", "height", height, "index", index,
+			"total", proof.Proof.Total, "results", len(results))
+		return nil, ErrLightClientVerify
+	}
+	return results[index], nil
+}
+
+// verifyProvedMsg check the swap message of the proved tx against the swap info parsed from the gateway's logs
+func (b *Bridge) verifyProvedMsg(tx sdk.Tx, swapInfo *tokens.SwapTxInfo, extra *SwapMemoExtra, result *abci.ResponseDeliverTx) error {
+	msgs := tx.GetMsgs()
+	logIndex := swapInfo.LogIndex
+	if logIndex < 1 || logIndex > len(msgs) {
+		return fmt.Errorf("%w: log index %v out of range", ErrLightClientVerify, logIndex)
+	}
+	erc20SwapInfo := swapInfo.ERC20SwapInfo
+
+	var sender, receiver, denom, memo string
+	amount := new(big.Int)
+	switch msg := msgs[logIndex-1].(type) {
+	case *bankTypes.MsgSend:
+		memoTx, ok := tx.(sdk.TxWithMemo)
+		if !ok {
+			return fmt.Errorf("%w: tx has no memo", ErrLightClientVerify)
+		}
+		sender, receiver, memo = msg.FromAddress, msg.ToAddress, memoTx.GetMemo()
+		denom = erc20SwapInfo.Token
+		if recvAmount := msg.Amount.AmountOfNoDenomValidation(denom); !recvAmount.IsNil() {
+			amount = recvAmount.BigInt()
+		}
+	case *channeltypes.MsgRecvPacket:
+		if err := checkRecvPacketResult(result, logIndex-1); err != nil {
+			return err
+		}
+		var data transfertypes.FungibleTokenPacketData
+		if err := json.Unmarshal(msg.Packet.Data, &data); err != nil {
+			return fmt.Errorf("%w: unmarshal packet data failed: %v", ErrLightClientVerify, err)
+		}
+		packet := msg.Packet
+		sender, receiver, memo = data.Sender, data.Receiver, data.Memo
+		denom = GetReceivedIBCDenom(packet.SourcePort, packet.SourceChannel, packet.DestinationPort, packet.DestinationChannel, data.Denom)
+		if _, ok := amount.SetString(data.Amount, 10); !ok {
+			return fmt.Errorf("%w: wrong packet amount %v", ErrLightClientVerify, data.Amount)
+		}
+	case *transfertypes.MsgTransfer:
+		// an outgoing ibc transfer escrows or burns the tokens on this chain, it never pays the mpc
+		return fmt.Errorf("%w: outgoing ibc transfer is not a deposit", ErrLightClientVerify)
+	default:
+		return fmt.Errorf("%w: unsupported message %T", ErrLightClientVerify, msg)
+	}
+
+	switch {
+	case denom != erc20SwapInfo.Token:
+		return fmt.Errorf("%w: denom mismatch, have %v want %v", ErrLightClientVerify, denom, erc20SwapInfo.Token)
+	case !common.IsEqualIgnoreCase(receiver, b.GetRouterContract(denom)):
+		return fmt.Errorf("%w: receiver %v is not mpc", ErrLightClientVerify, receiver)
+	case !common.IsEqualIgnoreCase(sender, swapInfo.From):
+		return fmt.Errorf("%w: sender mismatch, have %v want %v", ErrLightClientVerify, sender, swapInfo.From)
+	case swapInfo.Value == nil || amount.Cmp(swapInfo.Value) != 0:
+		return fmt.Errorf("%w: amount mismatch, have %v want %v", ErrLightClientVerify, amount, swapInfo.Value)
+	}
+
+	// the swap destination must be the one parsed from the proved memo
+	proved := &tokens.SwapTxInfo{SwapInfo: tokens.SwapInfo{ERC20SwapInfo: &tokens.ERC20SwapInfo{}}}
+	proved.Hash = swapInfo.Hash
+	proved.LogIndex = swapInfo.LogIndex
+	proved.FromChainID = swapInfo.FromChainID
+	provedExtra, err := ParseMemo(proved, memo)
+	if err != nil {
+		return fmt.Errorf("%w: parse memo failed: %v", ErrLightClientVerify, err)
+	}
+	if proved.Bind != swapInfo.Bind || proved.ToChainID.Cmp(swapInfo.ToChainID) != 0 ||
+		proved.ERC20SwapInfo.CallProxy != erc20SwapInfo.CallProxy ||
+		!bytes.Equal(proved.ERC20SwapInfo.CallData, erc20SwapInfo.CallData) ||
+		!reflect.DeepEqual(provedExtra, extra) {
+		return fmt.Errorf("%w: memo mismatch", ErrLightClientVerify)
+	}
+	return nil
+}
+
+// checkRecvPacketResult the packet is received by the message (not a no-op of a relayed packet)
+func checkRecvPacketResult(result *abci.ResponseDeliverTx, msgIndex int) error {
+	var txMsgData sdk.TxMsgData
+	if err := txMsgData.Unmarshal(result.Data); err != nil {
+		return fmt.Errorf("%w: unmarshal tx result data failed: %v", ErrLightClientVerify, err)
+	}
+	if msgIndex >= len(txMsgData.Data) {
+		return fmt.Errorf("%w: no result of message %v", ErrLightClientVerify, msgIndex)
+	}
+	var resp channeltypes.MsgRecvPacketResponse
+	if err := resp.Unmarshal(txMsgData.Data[msgIndex].Data); err != nil {
+		return fmt.Errorf("%w: unmarshal recv packet response failed: %v", ErrLightClientVerify, err)
+	}
+	if resp.Result != channeltypes.SUCCESS {
+		return fmt.Errorf("%w: recv packet result is %v", ErrLightClientVerify, resp.Result)
+	}
+	return nil
+}
+
+// getBlockResults get deliver tx results of block from tendermint rpc nodes
+func (b *Bridge) getBlockResults(ctx context.Context, height int64) (*ctypes.ResultBlockResults, error) {
+	var err error
+	for _, rpcClient := range grpcClients() {
+		queryCtx, cancel := withQueryTimeout(ctx, "GetBlockResults")
+		start := time.Now()
+		res, errq := rpcClient.BlockResults(queryCtx, &height)
+		recordGatewayQuery(rpcClient.URL, errq, time.Since(start))
+		cancel()
+		if errq == nil {
+			return res, nil
+		}
+		err = errq
+	}
+	if err == nil {
+		err = errors.New("no tendermint rpc clients")
+	}
+	log.Warn("get block results failed", "height", height, "err", err)
+	return nil, wrapRPCQueryError(err, "GetBlockResults", height)
+}
+
+// getTxProof get merkle proof of tx from tendermint rpc nodes
+func (b *Bridge) getTxProof(ctx context.Context, txHash []byte) (proof *types.TxProof, height int64, err error) {
+	for _, rpcClient := range grpcClients() {
+		queryCtx, cancel := withQueryTimeout(ctx, "GetTxProof")
+		start := time.Now()
+		res, errt := rpcClient.Tx(queryCtx, txHash, true)
+		recordGatewayQuery(rpcClient.URL, errt, time.Since(start))
+		cancel()
+		if errt == nil {
+			return &res.Proof, res.Height, nil
+		}
+		err = errt
+	}
+	if err == nil {
+		err = errors.New("no tendermint rpc clients")
+	}
+	log.Warn("get tx proof failed", "txHash", fmt.Sprintf("%X", txHash), "err", err)
+	return nil, 0, wrapRPCQueryError(err, "GetTxProof")
+}
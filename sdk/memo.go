@@ -0,0 +1,175 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/common/hexutil"
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/router"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+// SwapMemoVersion current version of json swap memo
+const SwapMemoVersion = 1
+
+// SwapMemo json swap memo, eg.
+// `{"v":1,"bind":"0x...","toChainID":"56","referral":"inj1...","callProxy":"0x...","callData":"0x...","nonce":"1"}`
+type SwapMemo struct {
+	Version   int    `json:"v"`
+	Bind      string `json:"bind"`
+	ToChainID string `json:"toChainID"`
+	Referral  string `json:"referral,omitempty"`
+	CallProxy string `json:"callProxy,omitempty"`
+	CallData  string `json:"callData,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+}
+
+// SwapMemoExtra fields of json swap memo which are not in router swap info
+type SwapMemoExtra struct {
+	Referral string `json:"referral,omitempty"`
+	Nonce    string `json:"nonce,omitempty"`
+}
+
+// SwapTxInfoWithExtra swap info with the json swap memo extra
+type SwapTxInfoWithExtra struct {
+	*tokens.SwapTxInfo
+	Extra *SwapMemoExtra `json:"extra,omitempty"`
+}
+
+// IsCallProxyAllowed the call proxy on dest chain is in the configed allow list
+func IsCallProxyAllowed(toChainID, callProxy string) bool {
+	cfg := config.GetServerConfig().SwapMemoConfig
+	if cfg == nil {
+		return false
+	}
+	for _, allowed := range cfg.AllowedCallProxies[toChainID] {
+		if strings.EqualFold(allowed, callProxy) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJSONMemo is memo of json format
+func IsJSONMemo(memo string) bool {
+	return strings.HasPrefix(strings.TrimSpace(memo), "{")
+}
+
+// ParseMemo parse swap memo of legacy format `bind:toChainID` or json format,
+// returns the json swap memo extra (nil if not exist)
+func ParseMemo(swapInfo *tokens.SwapTxInfo, memo string) (*SwapMemoExtra, error) {
+	if IsJSONMemo(memo) {
+		return parseJSONMemo(swapInfo, memo)
+	}
+	fields := strings.Split(memo, ":")
+	if len(fields) == 2 {
+		if toChainID, err := common.GetBigIntFromStr(fields[1]); err != nil {
+			return nil, err
+		} else {
+			dstBridge := router.GetBridgeByChainID(toChainID.String())
+			if dstBridge != nil && isValidBindAddress(dstBridge, fields[0]) {
+				swapInfo.Bind = fields[0]      // Bind
+				swapInfo.ToChainID = toChainID // ToChainID
+				swapInfo.To = swapInfo.Bind    // To
+				return nil, nil
+			}
+		}
+	}
+	return nil, tokens.ErrTxWithWrongMemo
+}
+
+// ParseSwapMemo parse and validate json swap memo strictly
+// (unknown fields, unknown version and malformed values are rejected)
+func ParseSwapMemo(memo string) (*SwapMemo, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(memo)))
+	decoder.DisallowUnknownFields()
+	var swapMemo SwapMemo
+	if err := decoder.Decode(&swapMemo); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	if err := swapMemo.Check(); err != nil {
+		return nil, err
+	}
+	return &swapMemo, nil
+}
+
+// Check check json swap memo
+func (m *SwapMemo) Check() error {
+	if m.Version != SwapMemoVersion {
+		return tokens.ErrTxWithWrongMemo
+	}
+	if m.Bind == "" {
+		return tokens.ErrWrongBindAddress
+	}
+	if _, err := strconv.ParseUint(m.ToChainID, 10, 64); err != nil {
+		return tokens.ErrTxWithWrongMemo
+	}
+	if (m.CallProxy == "") != (m.CallData == "") {
+		return tokens.ErrTxWithWrongMemo
+	}
+	if m.CallData != "" {
+		if _, err := hexutil.Decode(m.CallData); err != nil {
+			return tokens.ErrTxWithWrongMemo
+		}
+	}
+	if m.Nonce != "" {
+		if _, err := strconv.ParseUint(m.Nonce, 10, 64); err != nil {
+			return tokens.ErrTxWithWrongMemo
+		}
+	}
+	return nil
+}
+
+func parseJSONMemo(swapInfo *tokens.SwapTxInfo, memo string) (*SwapMemoExtra, error) {
+	swapMemo, err := ParseSwapMemo(memo)
+	if err != nil {
+		log.Debug("parse json memo failed", "memo", memo, "err", err)
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	dstBridge := router.GetBridgeByChainID(swapMemo.ToChainID)
+	if dstBridge == nil || !isValidBindAddress(dstBridge, swapMemo.Bind) {
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	if swapMemo.CallProxy != "" {
+		if !dstBridge.IsValidAddress(swapMemo.CallProxy) {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+		if !IsCallProxyAllowed(swapMemo.ToChainID, swapMemo.CallProxy) {
+			log.Debug("json memo call proxy is not allowed", "toChainID", swapMemo.ToChainID, "callProxy", swapMemo.CallProxy)
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+	}
+	if swapMemo.Referral != "" && swapInfo.FromChainID != nil {
+		srcBridge := router.GetBridgeByChainID(swapInfo.FromChainID.String())
+		if srcBridge == nil || !srcBridge.IsValidAddress(swapMemo.Referral) {
+			return nil, tokens.ErrTxWithWrongMemo
+		}
+	}
+	toChainID, err := common.GetBigIntFromStr(swapMemo.ToChainID)
+	if err != nil {
+		return nil, tokens.ErrTxWithWrongMemo
+	}
+	swapInfo.Bind = swapMemo.Bind  // Bind
+	swapInfo.ToChainID = toChainID // ToChainID
+	swapInfo.To = swapInfo.Bind    // To
+	// the nonce is chosen by user, it must not be used as the unique `SwapoutID`
+	if swapInfo.ERC20SwapInfo != nil {
+		swapInfo.ERC20SwapInfo.CallProxy = swapMemo.CallProxy
+		swapInfo.ERC20SwapInfo.CallData = common.FromHex(swapMemo.CallData)
+	}
+	if swapMemo.Referral == "" && swapMemo.Nonce == "" {
+		return nil, nil
+	}
+	return &SwapMemoExtra{
+		Referral: swapMemo.Referral,
+		Nonce:    swapMemo.Nonce,
+	}, nil
+}
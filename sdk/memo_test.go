@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+func TestParseSwapMemo(t *testing.T) {
+	tests := []struct {
+		name  string
+		memo  string
+		valid bool
+	}{
+		{"minimal", `{"v":1,"bind":"0x1111","toChainID":"56"}`, true},
+		{"all fields", `{"v":1,"bind":"0x1111","toChainID":"56","referral":"inj1","callProxy":"0x2222","callData":"0x1234","nonce":"1"}`, true},
+		{"unknown field", `{"v":1,"bind":"0x1111","toChainID":"56","swapoutID":"0x01"}`, false},
+		{"trailing data", `{"v":1,"bind":"0x1111","toChainID":"56"}{"v":1}`, false},
+		{"trailing garbage", `{"v":1,"bind":"0x1111","toChainID":"56"} x`, false},
+		{"wrong version", `{"v":2,"bind":"0x1111","toChainID":"56"}`, false},
+		{"missing version", `{"bind":"0x1111","toChainID":"56"}`, false},
+		{"empty bind", `{"v":1,"bind":"","toChainID":"56"}`, false},
+		{"wrong chain id", `{"v":1,"bind":"0x1111","toChainID":"0x38"}`, false},
+		{"call proxy without call data", `{"v":1,"bind":"0x1111","toChainID":"56","callProxy":"0x2222"}`, false},
+		{"call data without call proxy", `{"v":1,"bind":"0x1111","toChainID":"56","callData":"0x1234"}`, false},
+		{"wrong call data", `{"v":1,"bind":"0x1111","toChainID":"56","callProxy":"0x2222","callData":"1234"}`, false},
+		{"hex nonce", `{"v":1,"bind":"0x1111","toChainID":"56","nonce":"0x01"}`, false},
+		{"negative nonce", `{"v":1,"bind":"0x1111","toChainID":"56","nonce":"-1"}`, false},
+		{"overflow nonce", `{"v":1,"bind":"0x1111","toChainID":"56","nonce":"18446744073709551616"}`, false},
+		{"number nonce", `{"v":1,"bind":"0x1111","toChainID":"56","nonce":1}`, false},
+		{"not json", `0x1111:56`, false},
+	}
+	for _, test := range tests {
+		swapMemo, err := ParseSwapMemo(test.memo)
+		if test.valid && (err != nil || swapMemo == nil) {
+			t.Errorf("%v: parse memo failed: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: parse wrong memo success: %+v", test.name, swapMemo)
+		}
+	}
+}
+
+func TestIsCallProxyAllowed(t *testing.T) {
+	cfg := config.GetServerConfig()
+	backup := cfg.SwapMemoConfig
+	defer func() { cfg.SwapMemoConfig = backup }()
+
+	cfg.SwapMemoConfig = nil
+	if IsCallProxyAllowed("56", "0x2222") {
+		t.Error("call proxy is allowed without config")
+	}
+
+	cfg.SwapMemoConfig = &config.SwapMemoConfig{
+		AllowedCallProxies: map[string][]string{"56": {"0xABCD"}},
+	}
+	if !IsCallProxyAllowed("56", "0xabcd") {
+		t.Error("configed call proxy is not allowed")
+	}
+	if IsCallProxyAllowed("1", "0xabcd") {
+		t.Error("call proxy is allowed on other chain")
+	}
+	if IsCallProxyAllowed("56", "0x2222") {
+		t.Error("not configed call proxy is allowed")
+	}
+}
@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/router"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	"github.com/anyswap/RouterSDK-injective/config"
+	"github.com/anyswap/RouterSDK-injective/metrics"
+)
+
+// StartMetricsUpdater update balance metrics of mpc accounts periodically if metrics is enabled
+func StartMetricsUpdater(b *Bridge) {
+	cfg := config.GetServerConfig().MetricsConfig
+	if cfg == nil || !cfg.Enable {
+		return
+	}
+	utils.TopWaitGroup.Add(1)
+	go b.runMetricsUpdater(time.Duration(cfg.BalanceIntervalSeconds) * time.Second)
+}
+
+func (b *Bridge) runMetricsUpdater(interval time.Duration) {
+	defer utils.TopWaitGroup.Done()
+
+	for !utils.IsCleanuping() {
+		b.updateBalanceMetrics()
+		select {
+		case <-utils.CleanupChan:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+type mpcDenom struct {
+	mpc   string
+	denom string
+}
+
+// updateBalanceMetrics update balances of mpc accounts of the chain denom and tokens
+func (b *Bridge) updateBalanceMetrics() {
+	balances := make(map[mpcDenom]bool)
+	if mpc := b.ChainConfig.RouterContract; mpc != "" {
+		balances[mpcDenom{mpc: mpc, denom: b.Denom}] = true
+	}
+	for _, tokenID := range router.AllTokenIDs {
+		tokenAddr := router.GetCachedMultichainToken(tokenID, b.ChainConfig.ChainID)
+		if tokenAddr == "" {
+			continue
+		}
+		if mpc := b.GetRouterContract(tokenAddr); mpc != "" {
+			balances[mpcDenom{mpc: mpc, denom: tokenAddr}] = true
+		}
+	}
+	for key := range balances {
+		balance, err := b.GetDenomBalance(baseCtx, key.mpc, key.denom)
+		if err != nil {
+			log.Warn("update mpc balance metrics failed", "mpc", key.mpc, "denom", key.denom, "err", err)
+			continue
+		}
+		value, _ := new(big.Float).SetInt(balance.BigInt()).Float64()
+		metrics.SetMPCBalance(key.mpc, key.denom, value)
+	}
+}
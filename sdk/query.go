@@ -1,16 +1,19 @@
 package sdk
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
-	"github.com/anyswap/CrossChain-Router/v3/rpc/client"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
 )
 
 const (
@@ -20,6 +23,10 @@ const (
 	Balances    = "/cosmos/bank/v1beta1/balances/"
 	SimulateTx  = "/cosmos/tx/v1beta1/simulate"
 	BroadTx     = "/cosmos/tx/v1beta1/txs"
+	NodeConfig  = "/cosmos/base/node/v1beta1/config"
+	BlockByNum  = "/cosmos/base/tendermint/v1beta1/blocks/"
+
+	ChannelClientState = "/ibc/core/channel/v1/channels/%s/ports/%s/client_state"
 )
 
 var wrapRPCQueryError = tokens.WrapRPCQueryError
@@ -32,18 +39,33 @@ func joinURLPath(url, path string) string {
 	return url + path
 }
 
+// GetLatestBlockNumber impl
 func (b *Bridge) GetLatestBlockNumber() (uint64, error) {
-	if result, err := b.GRPCGetLatestBlockNumber(); err == nil {
+	return b.GetLatestBlockNumberWithContext(baseCtx)
+}
+
+// GetLatestBlockNumberWithContext get latest block number with context
+// the height received by event subscription is used if it's fresh
+func (b *Bridge) GetLatestBlockNumberWithContext(ctx context.Context) (uint64, error) {
+	if height, ok := cachedLatestHeight(); ok {
+		return height, nil
+	}
+	if result, err := b.GRPCGetLatestBlockNumber(ctx); err == nil {
+		observeLatestHeight(result)
 		return result, nil
-	} else if len(b.AllGatewayURLs) == 0 {
+	} else if len(b.restEndpoints()) == 0 {
 		return 0, err
 	}
 	var result *GetLatestBlockResponse
 	var err error
-	for _, url := range b.AllGatewayURLs {
+	for _, url := range b.restEndpoints() {
 		restApi := joinURLPath(url, LatestBlock)
-		if err = client.RPCGet(&result, restApi); err == nil {
+		start := time.Now()
+		err = restGet(ctx, "GetLatestBlockNumber", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
 			if height, err := strconv.ParseUint(result.Block.Header.Height, 10, 64); err == nil {
+				observeLatestHeight(height)
 				return height, nil
 			}
 		}
@@ -51,38 +73,47 @@ func (b *Bridge) GetLatestBlockNumber() (uint64, error) {
 	return 0, wrapRPCQueryError(err, "GetLatestBlockNumber")
 }
 
+// GetLatestBlockNumberOf impl
 func (b *Bridge) GetLatestBlockNumberOf(apiAddress string) (uint64, error) {
+	return b.GetLatestBlockNumberOfWithContext(baseCtx, apiAddress)
+}
+
+// GetLatestBlockNumberOfWithContext get latest block number of the gateway with context
+func (b *Bridge) GetLatestBlockNumberOfWithContext(ctx context.Context, apiAddress string) (uint64, error) {
 	if _, exist := rpcClientsMap[apiAddress]; exist {
-		if result, err := b.GRPCGetLatestBlockNumberOf(apiAddress); err == nil {
+		if result, err := b.GRPCGetLatestBlockNumberOf(ctx, apiAddress); err == nil {
 			return result, nil
-		} else if len(b.AllGatewayURLs) == 0 {
+		} else if len(b.restEndpoints()) == 0 {
 			return 0, err
 		}
 	}
 	var result *GetLatestBlockResponse
 	restApi := joinURLPath(apiAddress, LatestBlock)
-	if err := client.RPCGet(&result, restApi); err == nil {
+	if err := restGet(ctx, "GetLatestBlockNumber", &result, restApi); err == nil {
 		return strconv.ParseUint(result.Block.Header.Height, 10, 64)
 	} else {
 		return 0, wrapRPCQueryError(err, "GetLatestBlockNumber")
 	}
 }
 
-func (b *Bridge) GetChainID() (string, error) {
+func (b *Bridge) GetChainID(ctx context.Context) (string, error) {
 	if b.ChainName != "" {
 		return b.ChainName, nil
 	}
-	if result, err := b.GRPCGetChainID(); err == nil {
+	if result, err := b.GRPCGetChainID(ctx); err == nil {
 		b.ChainName = result
 		return b.ChainName, nil
-	} else if len(b.AllGatewayURLs) == 0 {
+	} else if len(b.restEndpoints()) == 0 {
 		return "", err
 	}
 	var result *GetLatestBlockResponse
 	var err error
-	for _, url := range b.AllGatewayURLs {
+	for _, url := range b.restEndpoints() {
 		restApi := joinURLPath(url, LatestBlock)
-		if err = client.RPCGet(&result, restApi); err == nil {
+		start := time.Now()
+		err = restGet(ctx, "GetChainID", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
 			b.ChainName = result.Block.Header.ChainID
 			return b.ChainName, nil
 		}
@@ -90,34 +121,56 @@ func (b *Bridge) GetChainID() (string, error) {
 	return "", wrapRPCQueryError(err, "GetChainID")
 }
 
-func (b *Bridge) GetTransactionByHash(txHash string) (*GetTxResponse, error) {
-	if result, err := b.GRPCGetTransactionByHash(txHash); err == nil {
+func (b *Bridge) GetTransactionByHash(ctx context.Context, txHash string) (*GetTxResponse, error) {
+	if result, err := b.GRPCGetTransactionByHash(ctx, txHash); err == nil {
 		return result, nil
-	} else if len(b.AllGatewayURLs) == 0 {
+	} else if len(b.restEndpoints()) == 0 {
 		return nil, err
 	}
 	var result *GetTxResponse
 	var err error
-	for _, url := range b.AllGatewayURLs {
+	for _, url := range b.restEndpoints() {
 		restApi := joinURLPath(url, TxByHash+txHash)
-		if err = client.RPCGet(&result, restApi); err == nil {
+		start := time.Now()
+		err = restGet(ctx, "GetTransactionByHash", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
 			return result, nil
 		}
 	}
 	return nil, wrapRPCQueryError(err, "GetTransactionByHash")
 }
 
-func (b *Bridge) GetBaseAccount(address string) (*QueryAccountResponse, error) {
-	if result, err := b.GRPCGetBaseAccount(address); err == nil {
+// GetTransactionByHashOf get tx from the gateway of api address
+func (b *Bridge) GetTransactionByHashOf(ctx context.Context, apiAddress, txHash string) (*GetTxResponse, error) {
+	if _, exist := rpcClientsMap[apiAddress]; exist {
+		return b.GRPCGetTransactionByHashOf(ctx, apiAddress, txHash)
+	}
+	var result *GetTxResponse
+	restApi := joinURLPath(apiAddress, TxByHash+txHash)
+	if err := restGet(ctx, "GetTransactionByHash", &result, restApi); err != nil {
+		return nil, wrapRPCQueryError(err, "GetTransactionByHash", txHash)
+	}
+	if result == nil || result.TxResponse == nil || result.Tx == nil {
+		return nil, wrapRPCQueryError(fmt.Errorf("empty tx response"), "GetTransactionByHash", txHash)
+	}
+	return result, nil
+}
+
+func (b *Bridge) GetBaseAccount(ctx context.Context, address string) (*QueryAccountResponse, error) {
+	if result, err := b.GRPCGetBaseAccount(ctx, address); err == nil {
 		return result, nil
-	} else if len(b.AllGatewayURLs) == 0 {
+	} else if len(b.restEndpoints()) == 0 {
 		return nil, err
 	}
 	var result *QueryAccountResponse
 	var err error
-	for _, url := range b.AllGatewayURLs {
+	for _, url := range b.restEndpoints() {
 		restApi := joinURLPath(url, AccountInfo+address)
-		if err = client.RPCGet(&result, restApi); err == nil {
+		start := time.Now()
+		err = restGet(ctx, "GetBaseAccount", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
 			return result, nil
 		} else {
 			log.Warn("GetBaseAccount failed", "url", restApi, "err", err)
@@ -126,17 +179,20 @@ func (b *Bridge) GetBaseAccount(address string) (*QueryAccountResponse, error) {
 	return nil, wrapRPCQueryError(err, "GetBaseAccount")
 }
 
-func (b *Bridge) GetDenomBalance(address, denom string) (sdk.Int, error) {
-	if result, err := b.GRPCGetDenomBalance(address, denom); err == nil {
+func (b *Bridge) GetDenomBalance(ctx context.Context, address, denom string) (sdk.Int, error) {
+	if result, err := b.GRPCGetDenomBalance(ctx, address, denom); err == nil {
 		return result, nil
-	} else if len(b.AllGatewayURLs) == 0 {
+	} else if len(b.restEndpoints()) == 0 {
 		return sdk.ZeroInt(), err
 	}
 	var result *QueryAllBalancesResponse
 	var err error
-	for _, url := range b.AllGatewayURLs {
+	for _, url := range b.restEndpoints() {
 		restApi := joinURLPath(url, Balances+address)
-		if err = client.RPCGet(&result, restApi); err == nil {
+		start := time.Now()
+		err = restGet(ctx, "GetDenomBalance", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
 			for _, coin := range result.Balances {
 				if coin.Denom == denom {
 					return coin.Amount, nil
@@ -150,53 +206,117 @@ func (b *Bridge) GetDenomBalance(address, denom string) (sdk.Int, error) {
 	return sdk.ZeroInt(), wrapRPCQueryError(err, "GetDenomBalance")
 }
 
-func (b *Bridge) SimulateTx(simulateReq *SimulateRequest) (string, error) {
-	if result, err := b.GRPCSimulateTx(simulateReq); err == nil {
-		return common.ToJSONString(result.GasInfo, false), nil
-	} else if len(b.AllGatewayURLs) == 0 {
-		return "", err
+// GetBlockTxs get tx results of block at height
+func (b *Bridge) GetBlockTxs(ctx context.Context, height uint64) ([]*BlockTx, error) {
+	if result, err := b.GRPCGetBlockTxs(ctx, height); err == nil {
+		return result, nil
+	} else if len(b.restEndpoints()) == 0 {
+		return nil, err
 	}
-	if data, err := json.Marshal(simulateReq); err != nil {
-		return "", err
-	} else {
-		for _, url := range b.AllGatewayURLs {
-			restApi := joinURLPath(url, SimulateTx)
-			if res, err := client.RPCRawPostWithTimeout(restApi, string(data), 120); err == nil && res != "" && res != "\n" {
-				return res, nil
+	var result *GetLatestBlockResponse
+	var err error
+	for _, url := range b.restEndpoints() {
+		restApi := joinURLPath(url, BlockByNum+fmt.Sprint(height))
+		start := time.Now()
+		err = restGet(ctx, "GetBlockTxs", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil && result != nil && result.Block != nil {
+			break
+		}
+	}
+	if err != nil || result == nil || result.Block == nil {
+		return nil, wrapRPCQueryError(err, "GetBlockTxs", height)
+	}
+	blockTxs := make([]*BlockTx, 0, len(result.Block.Data.Txs))
+	for _, tx := range result.Block.Data.Txs {
+		txBytes, err := base64.StdEncoding.DecodeString(tx)
+		if err != nil {
+			return nil, err
+		}
+		txHash := fmt.Sprintf("%X", Sha256Sum(txBytes))
+		txr, err := b.GetTransactionByHash(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		blockTxs = append(blockTxs, &BlockTx{
+			Hash:   txHash,
+			Code:   txr.TxResponse.Code,
+			Events: LogsToEvents(txr.TxResponse.MessageLogs()),
+		})
+	}
+	return blockTxs, nil
+}
+
+// GetMinGasPrices get minimum gas prices config of node
+func (b *Bridge) GetMinGasPrices(ctx context.Context) (sdk.DecCoins, error) {
+	if result, err := b.GRPCGetMinGasPrices(ctx); err == nil {
+		return sdk.ParseDecCoins(result)
+	} else if len(b.restEndpoints()) == 0 {
+		return nil, err
+	}
+	var result *QueryConfigResponse
+	var err error
+	for _, url := range b.restEndpoints() {
+		restApi := joinURLPath(url, NodeConfig)
+		start := time.Now()
+		err = restGet(ctx, "GetMinGasPrices", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
+			return sdk.ParseDecCoins(result.MinimumGasPrice)
+		}
+	}
+	return nil, wrapRPCQueryError(err, "GetMinGasPrices")
+}
+
+// GetChannelClientLatestHeight get latest height of the counterparty client of the ibc channel
+func (b *Bridge) GetChannelClientLatestHeight(ctx context.Context, portID, channelID string) (clienttypes.Height, error) {
+	if result, err := b.GRPCGetChannelClientLatestHeight(ctx, portID, channelID); err == nil {
+		return result, nil
+	} else if len(b.restEndpoints()) == 0 {
+		return clienttypes.ZeroHeight(), err
+	}
+	var result *QueryChannelClientStateResponse
+	var err error
+	for _, url := range b.restEndpoints() {
+		restApi := joinURLPath(url, fmt.Sprintf(ChannelClientState, channelID, portID))
+		start := time.Now()
+		err = restGet(ctx, "GetChannelClientLatestHeight", &result, restApi)
+		recordGatewayQuery(url, err, time.Since(start))
+		if err == nil {
+			if result == nil || result.IdentifiedClientState == nil || result.IdentifiedClientState.ClientState == nil {
+				err = fmt.Errorf("channel client state not found")
+				continue
 			}
+			return result.IdentifiedClientState.ClientState.LatestHeight.ToHeight()
 		}
-		return "", wrapRPCQueryError(err, "SimulateTx")
 	}
+	return clienttypes.ZeroHeight(), wrapRPCQueryError(err, "GetChannelClientLatestHeight", portID, channelID)
 }
 
-func (b *Bridge) BroadcastTx(req *BroadcastTxRequest) (string, error) {
-	if result, err := b.GRPCBroadcastTx(req); err == nil {
-		data, _ := json.Marshal(BroadcastTxResponse{
-			TxResponse: &TxResponse{
-				Height: fmt.Sprintf("%d", result.Height),
-				TxHash: result.TxHash,
-				Code:   result.Code,
-				Logs:   result.Logs,
+// SimulateTx simulate tx, returns json string of `SimulateResponse`
+func (b *Bridge) SimulateTx(ctx context.Context, simulateReq *SimulateRequest) (string, error) {
+	if result, err := b.GRPCSimulateTx(ctx, simulateReq); err == nil {
+		return common.ToJSONString(&SimulateResponse{
+			GasInfo: &GasInfo{
+				GasUsed: fmt.Sprintf("%d", result.GasInfo.GasUsed),
 			},
-		})
-		return string(data), nil
-	} else if len(b.AllGatewayURLs) == 0 {
+		}, false), nil
+	} else if len(b.restEndpoints()) == 0 {
 		return "", err
 	}
-	if data, err := json.Marshal(req); err != nil {
+	if data, err := json.Marshal(simulateReq); err != nil {
 		return "", err
 	} else {
 		var res string
-		var success bool
-		for _, url := range b.AllGatewayURLs {
-			restApi := joinURLPath(url, BroadTx)
-			if res, err = client.RPCJsonPostWithTimeout(restApi, string(data), 120); err == nil {
-				success = true
+		for _, url := range b.restEndpoints() {
+			restApi := joinURLPath(url, SimulateTx)
+			start := time.Now()
+			res, err = restPost(ctx, "SimulateTx", restApi, data)
+			recordGatewayQuery(url, err, time.Since(start))
+			if err == nil && res != "" && res != "\n" {
+				return res, nil
 			}
 		}
-		if success {
-			return res, nil
-		}
-		return "", wrapRPCQueryError(err, "BroadcastTx")
+		return "", wrapRPCQueryError(err, "SimulateTx")
 	}
 }
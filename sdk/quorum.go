@@ -0,0 +1,216 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+var (
+	// ErrQuorumMismatch different results are each returned by enough nodes
+	ErrQuorumMismatch = errors.New("quorum results mismatch")
+
+	// not enough results is treated as rpc query error (retry later)
+	errQuorumNotReached = errors.New("quorum not reached")
+)
+
+// quorumEndpoints number of endpoints which must agree, 0 means quorum reads are disabled
+func quorumEndpoints() int {
+	cfg := config.GetServerConfig().QuorumConfig
+	if cfg == nil || !cfg.Enable {
+		return 0
+	}
+	return cfg.Endpoints
+}
+
+// allEndpoints distinct available grpc and rest endpoints
+func (b *Bridge) allEndpoints() []string {
+	var endpoints []string
+	exists := make(map[string]bool)
+	for _, url := range append(grpcEndpoints(), b.restEndpoints()...) {
+		if !exists[url] {
+			exists[url] = true
+			endpoints = append(endpoints, url)
+		}
+	}
+	return endpoints
+}
+
+// endpointNode the node of endpoint (the host), the grpc and rest endpoints of a node are not independent
+func endpointNode(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return endpoint
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// countNodes number of distinct nodes of endpoints
+func countNodes(endpoints []string) int {
+	nodes := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		nodes[endpointNode(endpoint)] = true
+	}
+	return len(nodes)
+}
+
+// getTransactionForVerify get tx by hash, by quorum reads if enabled
+func (b *Bridge) getTransactionForVerify(ctx context.Context, txHash string) (*GetTxResponse, error) {
+	if n := quorumEndpoints(); n > 0 {
+		return b.GetTransactionByHashQuorum(ctx, txHash, n)
+	}
+	return b.GetTransactionByHash(ctx, txHash)
+}
+
+// getLatestBlockNumberForVerify get latest block number, by quorum reads if enabled
+func (b *Bridge) getLatestBlockNumberForVerify(ctx context.Context) (uint64, error) {
+	if n := quorumEndpoints(); n > 0 {
+		return b.GetLatestBlockNumberQuorum(ctx, n)
+	}
+	return b.GetLatestBlockNumberWithContext(ctx)
+}
+
+// GetTransactionByHashQuorum get tx from all endpoints concurrently,
+// at least n distinct nodes must return the same tx (agree on code, height, memo and transfers,
+// include received ibc transfers). disagreeing results without n agreeing nodes are retryable query errors.
+func (b *Bridge) GetTransactionByHashQuorum(ctx context.Context, txHash string, n int) (*GetTxResponse, error) {
+	endpoints := b.allEndpoints()
+	if nodes := countNodes(endpoints); nodes < n {
+		return nil, wrapRPCQueryError(fmt.Errorf("%w: have %v nodes, need %v", errQuorumNotReached, nodes, n), "GetTransactionByHashQuorum", txHash)
+	}
+	results := make([]*GetTxResponse, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, url := range endpoints {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			start := time.Now()
+			results[i], errs[i] = b.GetTransactionByHashOf(ctx, url, txHash)
+			recordGatewayQuery(url, errs[i], time.Since(start))
+		}(i, url)
+	}
+	wg.Wait()
+
+	// fingerprint => nodes returned it
+	votes := make(map[string]map[string]bool)
+	responses := make(map[string]*GetTxResponse)
+	for i, res := range results {
+		if errs[i] != nil {
+			log.Debug("quorum get tx failed", "txHash", txHash, "url", endpoints[i], "err", errs[i])
+			continue
+		}
+		fingerprint := txFingerprint(res)
+		if votes[fingerprint] == nil {
+			votes[fingerprint] = make(map[string]bool)
+			responses[fingerprint] = res
+		}
+		votes[fingerprint][endpointNode(endpoints[i])] = true
+	}
+
+	var agreed []string
+	for fingerprint, nodes := range votes {
+		if len(nodes) >= n {
+			agreed = append(agreed, fingerprint)
+		}
+	}
+	if len(votes) > 1 {
+		log.Warn("quorum get tx results disagree", "txHash", txHash, "results", len(votes), "agreed", len(agreed))
+	}
+	switch len(agreed) {
+	case 1:
+		return responses[agreed[0]], nil
+	case 0:
+		if len(votes) > 1 {
+			return nil, wrapRPCQueryError(fmt.Errorf("%w: %v different results", errQuorumNotReached, len(votes)), "GetTransactionByHashQuorum", txHash)
+		}
+		return nil, wrapRPCQueryError(fmt.Errorf("%w: less than %v nodes returned tx", errQuorumNotReached, n), "GetTransactionByHashQuorum", txHash)
+	default:
+		log.Error("quorum get tx results mismatch", "txHash", txHash, "results", agreed)
+		return nil, ErrQuorumMismatch
+	}
+}
+
+// GetLatestBlockNumberQuorum get latest block number which at least n distinct nodes have reached
+func (b *Bridge) GetLatestBlockNumberQuorum(ctx context.Context, n int) (uint64, error) {
+	endpoints := b.allEndpoints()
+	heights := make([]uint64, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, url := range endpoints {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			start := time.Now()
+			heights[i], errs[i] = b.GetLatestBlockNumberOfWithContext(ctx, url)
+			recordGatewayQuery(url, errs[i], time.Since(start))
+		}(i, url)
+	}
+	wg.Wait()
+
+	// the highest height of each node
+	nodeHeights := make(map[string]uint64)
+	for i, height := range heights {
+		if errs[i] != nil {
+			continue
+		}
+		if node := endpointNode(endpoints[i]); height > nodeHeights[node] {
+			nodeHeights[node] = height
+		}
+	}
+	if len(nodeHeights) < n {
+		return 0, wrapRPCQueryError(fmt.Errorf("%w: %v of %v nodes returned height", errQuorumNotReached, len(nodeHeights), n), "GetLatestBlockNumberQuorum")
+	}
+	okHeights := make([]uint64, 0, len(nodeHeights))
+	for _, height := range nodeHeights {
+		okHeights = append(okHeights, height)
+	}
+	sort.Slice(okHeights, func(i, j int) bool { return okHeights[i] > okHeights[j] })
+	return okHeights[n-1], nil
+}
+
+type txSummary struct {
+	Code      uint32
+	Codespace string
+	Height    string
+	Memo      string
+	Messages  []*messageSummary
+}
+
+type messageSummary struct {
+	Transfers  []*Transfer
+	IBCDeposit *IBCDeposit
+	IBCError   string
+}
+
+// txFingerprint summary of tx fields which must agree between endpoints
+func txFingerprint(res *GetTxResponse) string {
+	summary := &txSummary{
+		Code:      res.TxResponse.Code,
+		Codespace: res.TxResponse.Codespace,
+		Height:    res.TxResponse.Height,
+	}
+	if res.Tx != nil {
+		summary.Memo = res.Tx.Body.Memo
+	}
+	for _, messageLog := range res.TxResponse.MessageLogs() {
+		msg := &messageSummary{Transfers: GetTransfers(messageLog)}
+		deposit, err := ParseIBCDeposit(messageLog)
+		if err != nil {
+			msg.IBCError = err.Error()
+		}
+		msg.IBCDeposit = deposit
+		summary.Messages = append(summary.Messages, msg)
+	}
+	data, _ := json.Marshal(summary)
+	return string(data)
+}
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"errors"
 	"strconv"
 
@@ -10,18 +11,33 @@ import (
 
 // RegisterSwap api
 func (b *Bridge) RegisterSwap(txHash string, args *tokens.RegisterArgs) ([]*tokens.SwapTxInfo, []error) {
+	return b.RegisterSwapWithContext(baseCtx, txHash, args)
+}
+
+// RegisterSwapWithContext register swap with context
+func (b *Bridge) RegisterSwapWithContext(ctx context.Context, txHash string, args *tokens.RegisterArgs) ([]*tokens.SwapTxInfo, []error) {
+	swapInfos, errs := b.RegisterSwapWithExtra(ctx, txHash, args)
+	result := make([]*tokens.SwapTxInfo, len(swapInfos))
+	for i, swapInfo := range swapInfos {
+		result[i] = swapInfo.SwapTxInfo
+	}
+	return result, errs
+}
+
+// RegisterSwapWithExtra register swap with context, and returns the json swap memo extra of the verified swaps
+func (b *Bridge) RegisterSwapWithExtra(ctx context.Context, txHash string, args *tokens.RegisterArgs) ([]*SwapTxInfoWithExtra, []error) {
 	swapType := args.SwapType
 	logIndex := args.LogIndex
 
 	switch swapType {
 	case tokens.ERC20SwapType:
-		return b.registerERC20SwapTx(txHash, logIndex)
+		return b.registerERC20SwapTx(ctx, txHash, logIndex)
 	default:
 		return nil, []error{tokens.ErrSwapTypeNotSupported}
 	}
 }
 
-func (b *Bridge) registerERC20SwapTx(txHash string, logIndex int) ([]*tokens.SwapTxInfo, []error) {
+func (b *Bridge) registerERC20SwapTx(ctx context.Context, txHash string, logIndex int) ([]*SwapTxInfoWithExtra, []error) {
 	log.Info("registerERC20SwapTx", "txhash:", txHash, "logIndex:", logIndex)
 	commonInfo := &tokens.SwapTxInfo{SwapInfo: tokens.SwapInfo{ERC20SwapInfo: &tokens.ERC20SwapInfo{}}}
 	commonInfo.SwapType = tokens.ERC20SwapType          // SwapType
@@ -29,52 +45,52 @@ func (b *Bridge) registerERC20SwapTx(txHash string, logIndex int) ([]*tokens.Swa
 	commonInfo.LogIndex = logIndex                      // LogIndex
 	commonInfo.FromChainID = b.ChainConfig.GetChainID() // FromChainID
 
-	if txres, err := b.GetTransactionByHash(txHash); err != nil {
-		return []*tokens.SwapTxInfo{commonInfo}, []error{err}
+	if txres, err := b.GetTransactionByHash(ctx, txHash); err != nil {
+		return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{err}
 	} else {
 		if txHeight, err := strconv.ParseUint(txres.TxResponse.Height, 10, 64); err != nil {
-			return []*tokens.SwapTxInfo{commonInfo}, []error{err}
+			return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{err}
 		} else {
 			commonInfo.Height = txHeight
 		}
 		if txres.TxResponse.Code != 0 {
-			return []*tokens.SwapTxInfo{commonInfo}, []error{tokens.ErrTxWithWrongStatus}
-		}
-		if err := ParseMemo(commonInfo, txres.Tx.Body.Memo); err != nil {
-			return []*tokens.SwapTxInfo{commonInfo}, []error{err}
+			return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{tokens.ErrTxWithWrongStatus}
 		}
-		swapInfos := make([]*tokens.SwapTxInfo, 0)
+		swapInfos := make([]*SwapTxInfoWithExtra, 0)
 		errs := make([]error, 0)
-		startIndex, endIndex := 1, len(txres.TxResponse.Logs)+1
+		messageLogs := txres.TxResponse.MessageLogs()
+		startIndex, endIndex := 1, len(messageLogs)+1
 		if logIndex != 0 {
 			if logIndex >= endIndex || logIndex < 0 {
-				return []*tokens.SwapTxInfo{commonInfo}, []error{tokens.ErrLogIndexOutOfRange}
+				return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{tokens.ErrLogIndexOutOfRange}
 			}
 			startIndex = logIndex
 			endIndex = logIndex + 1
 		}
+		var parseErr error
 		for i := startIndex; i < endIndex; i++ {
 			swapInfo := &tokens.SwapTxInfo{}
 			*swapInfo = *commonInfo
 			swapInfo.ERC20SwapInfo = &tokens.ERC20SwapInfo{}
 			swapInfo.LogIndex = i // LogIndex
-			if err := b.ParseAmountTotal(txres.TxResponse.Logs[swapInfo.LogIndex-1], swapInfo); err == nil {
-				switch {
-				case errors.Is(err, tokens.ErrSwapoutLogNotFound),
-					errors.Is(err, tokens.ErrTxWithWrongTopics),
-					errors.Is(err, tokens.ErrTxWithWrongContract):
-				case err == nil:
-					err = b.checkSwapoutInfo(swapInfo)
-				default:
-					log.Debug(b.ChainConfig.BlockChain+" register router swap error", "txHash", txHash, "logIndex", swapInfo.LogIndex, "err", err)
-				}
-				swapInfos = append(swapInfos, swapInfo)
-				errs = append(errs, err)
+			extra, err := b.parseSwapInfo(messageLogs[swapInfo.LogIndex-1], txres.Tx.Body.Memo, swapInfo)
+			if err != nil {
+				parseErr = err
+				continue
 			}
+			if err = b.checkSwapoutInfo(swapInfo); err != nil {
+				log.Debug(b.ChainConfig.BlockChain+" register router swap error", "txHash", txHash, "logIndex", swapInfo.LogIndex, "err", err)
+				extra = nil
+			}
+			swapInfos = append(swapInfos, &SwapTxInfoWithExtra{SwapTxInfo: swapInfo, Extra: extra})
+			errs = append(errs, err)
 		}
 
 		if len(swapInfos) == 0 {
-			return []*tokens.SwapTxInfo{commonInfo}, []error{tokens.ErrSwapoutLogNotFound}
+			if errors.Is(parseErr, tokens.ErrTxWithWrongMemo) {
+				return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{parseErr}
+			}
+			return []*SwapTxInfoWithExtra{{SwapTxInfo: commonInfo}}, []error{tokens.ErrSwapoutLogNotFound}
 		}
 		return swapInfos, errs
 	}
@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+)
+
+// Rescan verify deposits in blocks of height range [start, end) by `jobs` workers,
+// and report the result of each found deposit, or the error of block failed to scan.
+func (b *Bridge) Rescan(start, end uint64, jobs int, report func(*ScanResult)) {
+	if jobs <= 0 {
+		jobs = 1
+	}
+	var reportLock sync.Mutex
+	doReport := func(result *ScanResult) {
+		reportLock.Lock()
+		defer reportLock.Unlock()
+		report(result)
+	}
+
+	heights := make(chan uint64, jobs)
+	wg := new(sync.WaitGroup)
+	wg.Add(jobs)
+	for i := 0; i < jobs; i++ {
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				results, err := b.rescanBlock(height)
+				if err != nil {
+					log.Warn("rescan block failed", "height", height, "err", err)
+					doReport(&ScanResult{Height: height, Error: err.Error(), Err: err})
+					continue
+				}
+				for _, result := range results {
+					doReport(result)
+				}
+			}
+		}()
+	}
+
+	for height := start; height < end; height++ {
+		if utils.IsCleanuping() {
+			break
+		}
+		heights <- height
+		if (height-start)%1000 == 0 {
+			log.Info("rescan progress", "height", height, "start", start, "end", end)
+		}
+	}
+	close(heights)
+	wg.Wait()
+}
+
+func (b *Bridge) rescanBlock(height uint64) (results []*ScanResult, err error) {
+	for i := 0; i < retryRPCCount; i++ {
+		if results, err = b.ScanBlockDeposits(height); err == nil {
+			return results, nil
+		}
+		time.Sleep(retryRPCInterval)
+	}
+	return nil, err
+}
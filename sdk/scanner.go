@@ -0,0 +1,292 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/CrossChain-Router/v3/router"
+	"github.com/anyswap/CrossChain-Router/v3/rpc/client"
+	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	"github.com/anyswap/RouterSDK-injective/config"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+)
+
+// ScanCallback is called with each verified swap found by the scanner,
+// the block is scanned again later if it returns error.
+type ScanCallback func(swapInfo *SwapTxInfoWithExtra) error
+
+// Scanner follows new blocks, finds txs paying the router mpc and registers them
+type Scanner struct {
+	bridge   *Bridge
+	cfg      *config.ScanConfig
+	callback ScanCallback
+}
+
+// ScanResult verify result of deposit found by scanning block
+type ScanResult struct {
+	Height   uint64               `json:"height"`
+	TxHash   string               `json:"txhash,omitempty"`
+	LogIndex int                  `json:"logIndex"`
+	Matched  bool                 `json:"matched"`
+	Error    string               `json:"error,omitempty"`
+	SwapInfo *SwapTxInfoWithExtra `json:"swapInfo,omitempty"`
+
+	Err error `json:"-"`
+}
+
+type routerSwapKeyArgs struct {
+	ChainID  string `json:"chainid"`
+	TxID     string `json:"txid"`
+	LogIndex string `json:"logindex"`
+}
+
+var scanCallback ScanCallback
+
+// SetScanCallback set callback of found swaps (replaces the default router server and webhook delivery)
+func SetScanCallback(callback ScanCallback) {
+	scanCallback = callback
+}
+
+// StartScanner start block scanner if enabled
+func StartScanner(b *Bridge) {
+	cfg := config.GetServerConfig().ScanConfig
+	if cfg == nil || !cfg.Enable {
+		return
+	}
+	s := NewScanner(b, cfg)
+	utils.TopWaitGroup.Add(1)
+	go s.Run()
+}
+
+// NewScanner new scanner
+func NewScanner(b *Bridge, cfg *config.ScanConfig) *Scanner {
+	s := &Scanner{
+		bridge:   b,
+		cfg:      cfg,
+		callback: scanCallback,
+	}
+	if s.callback == nil {
+		s.callback = s.deliverSwap
+	}
+	return s
+}
+
+// Run scan stable blocks (with enough confirmations) until cleanup
+func (s *Scanner) Run() {
+	defer utils.TopWaitGroup.Done()
+
+	b := s.bridge
+	chainID := b.ChainConfig.ChainID
+	next, err := s.getStartHeight()
+	if err != nil {
+		log.Error("scanner get start height failed", "chainID", chainID, "err", err)
+		return
+	}
+	log.Info("scanner start", "chainID", chainID, "startHeight", next, "checkpoint", s.cfg.CheckpointFile)
+
+	for !utils.IsCleanuping() {
+		stableHeight, err := s.bridge.GetStableHeight()
+		if err != nil {
+			log.Warn("scanner get latest block number failed", "chainID", chainID, "err", err)
+		}
+		for ; err == nil && next <= stableHeight; next++ {
+			if utils.IsCleanuping() {
+				return
+			}
+			if err = s.scanBlock(next); err != nil {
+				log.Warn("scan block failed", "chainID", chainID, "height", next, "err", err)
+				break
+			}
+			if err = s.saveCheckpoint(next); err != nil {
+				log.Error("scanner save checkpoint failed", "chainID", chainID, "height", next, "err", err)
+				break
+			}
+		}
+		select {
+		case <-utils.CleanupChan:
+			return
+		case <-time.After(time.Duration(s.cfg.IntervalSeconds) * time.Second):
+		}
+	}
+}
+
+// GetStableHeight blocks below or at the stable height have enough confirmations
+func (b *Bridge) GetStableHeight() (uint64, error) {
+	latest, err := b.GetLatestBlockNumber()
+	if err != nil {
+		return 0, err
+	}
+	confirmations := b.GetChainConfig().Confirmations
+	if latest < confirmations {
+		return 0, nil
+	}
+	return latest - confirmations, nil
+}
+
+// getStartHeight start from the next height of checkpoint,
+// or the configed start height (default is the current stable height)
+func (s *Scanner) getStartHeight() (start uint64, err error) {
+	checkpoint, exist, err := s.loadCheckpoint()
+	switch {
+	case err != nil:
+		return 0, err
+	case exist:
+		start = checkpoint + 1
+	case s.cfg.StartHeight > 0:
+		start = s.cfg.StartHeight
+	default:
+		if start, err = s.bridge.GetStableHeight(); err != nil {
+			return 0, err
+		}
+	}
+	if initialHeight := s.bridge.ChainConfig.InitialHeight; start < initialHeight {
+		start = initialHeight
+	}
+	return start, nil
+}
+
+func (s *Scanner) loadCheckpoint() (height uint64, exist bool, err error) {
+	data, err := os.ReadFile(s.cfg.CheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	height, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("wrong checkpoint file content: %w", err)
+	}
+	return height, true, nil
+}
+
+// saveCheckpoint write to temp file and rename to keep the checkpoint file complete
+func (s *Scanner) saveCheckpoint(height uint64) error {
+	tmpFile := s.cfg.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(fmt.Sprintf("%d\n", height)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.cfg.CheckpointFile)
+}
+
+func (s *Scanner) scanBlock(height uint64) error {
+	results, err := s.bridge.ScanBlockDeposits(height)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			if errors.Is(result.Err, tokens.ErrRPCQueryError) {
+				return result.Err
+			}
+			log.Info("scanner ignore swap", "txHash", result.TxHash, "logIndex", result.LogIndex, "err", result.Err)
+			continue
+		}
+		if err := s.callback(result.SwapInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanBlockDeposits find txs paying the router mpc in block, and verify them by `RegisterSwap`
+func (b *Bridge) ScanBlockDeposits(height uint64) ([]*ScanResult, error) {
+	blockTxs, err := b.GetBlockTxs(baseCtx, height)
+	if err != nil {
+		return nil, err
+	}
+	mpcs := b.getRouterMPCs()
+	var results []*ScanResult
+	for _, tx := range blockTxs {
+		if tx.Code != 0 || !IsDepositEvents(tx.Events, mpcs) {
+			continue
+		}
+		swapInfos, errs := b.RegisterSwapWithExtra(baseCtx, tx.Hash, &tokens.RegisterArgs{SwapType: tokens.ERC20SwapType})
+		for i, swapInfo := range swapInfos {
+			result := &ScanResult{
+				Height:   height,
+				TxHash:   tx.Hash,
+				LogIndex: swapInfo.LogIndex,
+				Err:      errs[i],
+			}
+			if errs[i] == nil {
+				result.Matched = true
+				result.SwapInfo = swapInfo
+			} else {
+				result.Error = errs[i].Error()
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// deliverSwap register swap to router server and post swap info to webhook
+func (s *Scanner) deliverSwap(swapInfo *SwapTxInfoWithExtra) error {
+	if s.cfg.RouterServerURL != "" {
+		args := &routerSwapKeyArgs{
+			ChainID:  swapInfo.FromChainID.String(),
+			TxID:     swapInfo.Hash,
+			LogIndex: fmt.Sprintf("%d", swapInfo.LogIndex),
+		}
+		var result interface{}
+		err := client.RPCPost(&result, s.cfg.RouterServerURL, "swap.RegisterRouterSwap", args)
+		if err != nil && !strings.Contains(err.Error(), "already registered") {
+			return err
+		}
+	}
+	if s.cfg.WebhookURL != "" {
+		if _, err := client.RPCJsonPostWithTimeout(s.cfg.WebhookURL, common.ToJSONString(swapInfo, false), 60); err != nil {
+			return err
+		}
+	}
+	log.Info("scanner found swap", "txHash", swapInfo.Hash, "logIndex", swapInfo.LogIndex,
+		"height", swapInfo.Height, "bind", swapInfo.Bind, "toChainID", swapInfo.ToChainID, "value", swapInfo.Value)
+	return nil
+}
+
+// getRouterMPCs get router mpc addresses of chain and tokens
+func (b *Bridge) getRouterMPCs() map[string]bool {
+	mpcs := make(map[string]bool)
+	if routerContract := b.ChainConfig.RouterContract; routerContract != "" {
+		mpcs[routerContract] = true
+	}
+	for _, tokenID := range router.AllTokenIDs {
+		tokenAddr := router.GetCachedMultichainToken(tokenID, b.ChainConfig.ChainID)
+		if tokenAddr == "" {
+			continue
+		}
+		if routerContract := b.GetRouterContract(tokenAddr); routerContract != "" {
+			mpcs[routerContract] = true
+		}
+	}
+	return mpcs
+}
+
+// IsDepositEvents has transfer (or received ics20 packet) to any of the mpcs
+func IsDepositEvents(events []Event, mpcs map[string]bool) bool {
+	for _, event := range events {
+		var receiverKey string
+		switch event.Type {
+		case TransferType:
+			receiverKey = "recipient"
+		case transfertypes.EventTypePacket:
+			receiverKey = transfertypes.AttributeKeyReceiver
+		default:
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == receiverKey && mpcs[attr.Value] {
+				return true
+			}
+		}
+	}
+	return false
+}
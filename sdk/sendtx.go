@@ -1,15 +1,68 @@
 package sdk
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"time"
 
+	"github.com/anyswap/CrossChain-Router/v3/log"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
+	"github.com/anyswap/RouterSDK-injective/config"
+	"github.com/anyswap/RouterSDK-injective/metrics"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
 
-// SendTransaction send signed tx
+// SendTransaction send signed tx,
+// wait until the tx is included in block if `WaitCommitted` of broadcast config is set.
+// the broadcasted tx is not reported as failed if it is not included in time,
+// as it may still be included later.
 func (b *Bridge) SendTransaction(signedTx interface{}) (string, error) {
+	return b.SendTransactionWithContext(baseCtx, signedTx)
+}
+
+// SendTransactionWithContext send signed tx with context.
+// the broadcast is not canceled by ctx, which only stops the waiting,
+// so that the tx is still submitted to all endpoints after the first one accepted it.
+func (b *Bridge) SendTransactionWithContext(ctx context.Context, signedTx interface{}) (string, error) {
+	txHash, err := b.broadcastSignedTx(signedTx)
+	if err != nil {
+		return "", err
+	}
+	if cfg := config.GetServerConfig().BroadcastConfig; cfg != nil && cfg.WaitCommitted {
+		timeout := time.Duration(cfg.CommitTimeoutSeconds) * time.Second
+		result, err := b.WaitTxCommitted(ctx, txHash, timeout)
+		if result == nil && err != nil {
+			log.Warn("sent tx is not committed in time", "txhash", txHash, "timeout", timeout, "err", err)
+			return txHash, nil
+		}
+		if err != nil {
+			return txHash, err
+		}
+	}
+	return txHash, nil
+}
+
+// SendTransactionAndWait send signed tx and wait until the tx is included in block.
+// the broadcast is not canceled by ctx, which only stops the waiting.
+func (b *Bridge) SendTransactionAndWait(ctx context.Context, signedTx interface{}, timeout time.Duration) (*TxCommitResult, error) {
+	txHash, err := b.broadcastSignedTx(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	return b.WaitTxCommitted(ctx, txHash, timeout)
+}
+
+// broadcastSignedTx broadcast signed tx (sync mode)
+func (b *Bridge) broadcastSignedTx(signedTx interface{}) (txHash string, err error) {
+	defer func() {
+		outcome := "accepted"
+		if err != nil {
+			outcome = string(GetBroadcastAction(err))
+		}
+		metrics.ObserveBroadcast(outcome)
+	}()
 	if txBytes, ok := signedTx.([]byte); !ok {
 		return "", errors.New("wrong signed transaction type")
 	} else {
@@ -17,7 +70,7 @@ func (b *Bridge) SendTransaction(signedTx interface{}) (string, error) {
 			TxBytes: string(txBytes),
 			Mode:    "BROADCAST_MODE_SYNC",
 		}
-		if txRes, err := b.BroadcastTx(req); err != nil {
+		if txRes, err := b.BroadcastTx(baseCtx, req); err != nil {
 			return "", err
 		} else {
 			if txRes == "" {
@@ -27,10 +80,70 @@ func (b *Bridge) SendTransaction(signedTx interface{}) (string, error) {
 			if err := json.Unmarshal([]byte(txRes), &txResponse); err != nil {
 				return "", err
 			}
-			if txResponse.TxResponse.Code != 0 && txResponse.TxResponse.Code != 19 {
-				return "", fmt.Errorf("SendTransaction error, code: %v", txResponse.TxResponse.Code)
+			if txResponse == nil || txResponse.TxResponse == nil {
+				return "", tokens.ErrBroadcastTx
+			}
+			signerSeqs := b.getSignerSequences(txBytes)
+			if txResponse.TxResponse.Code != 0 {
+				broadcastErr := NewBroadcastError(txResponse.TxResponse)
+				if errors.Is(broadcastErr, ErrBroadcastWrongSequence) {
+					for _, signerSeq := range signerSeqs {
+						b.AccountCache.InvalidateSequence(signerSeq.Address)
+					}
+				}
+				if !errors.Is(broadcastErr, ErrBroadcastTxInMempool) {
+					// the tx is not in mempool, the sequence can be reused
+					for _, signerSeq := range signerSeqs {
+						b.SequenceManager.Release(signerSeq.Address, signerSeq.Sequence)
+					}
+					log.Warn("broadcast tx failed", "txhash", broadcastErr.TxHash, "err", broadcastErr)
+					return "", broadcastErr
+				}
+			}
+			for _, signerSeq := range signerSeqs {
+				b.SequenceManager.MarkPending(signerSeq.Address, signerSeq.Sequence, txResponse.TxResponse.TxHash)
 			}
 			return txResponse.TxResponse.TxHash, nil
 		}
 	}
 }
+
+type signerSequence struct {
+	Address  string
+	Sequence uint64
+}
+
+// getSignerSequences get signers and their sequences of base64 encoded tx
+func (b *Bridge) getSignerSequences(txBytes []byte) []*signerSequence {
+	rawTx, err := base64.StdEncoding.DecodeString(string(txBytes))
+	if err != nil {
+		log.Warn("decode base64 tx failed", "err", err)
+		return nil
+	}
+	tx, err := b.TxConfig.TxDecoder()(rawTx)
+	if err != nil {
+		log.Warn("decode tx failed", "err", err)
+		return nil
+	}
+	sigTx, ok := tx.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil
+	}
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		log.Warn("get tx signatures failed", "err", err)
+		return nil
+	}
+	signers := sigTx.GetSigners()
+	if len(signers) != len(sigs) {
+		return nil
+	}
+	result := make([]*signerSequence, 0, len(sigs))
+	for i, sig := range sigs {
+		result = append(result, &signerSequence{
+			Address:  signers[i].String(),
+			Sequence: sig.Sequence,
+		})
+	}
+	return result
+}
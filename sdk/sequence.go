@@ -0,0 +1,268 @@
+package sdk
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+)
+
+var (
+	// SequenceReservationTimeout release reserved sequence if the tx is not sent in time (abandoned)
+	SequenceReservationTimeout = 10 * time.Minute
+	// PendingTxTimeout release sequence of sent tx if it is not included in block in time (dropped)
+	PendingTxTimeout = 10 * time.Minute
+	// SequenceReconcileInterval interval of checking new blocks to reconcile sequences
+	SequenceReconcileInterval = 3 * time.Second
+)
+
+// SequenceManager allocates sequences locally, so several txs can be built
+// before the previous ones are included in block.
+type SequenceManager struct {
+	lock     sync.Mutex
+	accounts map[string]*accountSequences // key is lower case address
+}
+
+type accountSequences struct {
+	chainSeq     uint64 // latest sequence on chain
+	next         uint64 // next never allocated sequence
+	reservations map[uint64]*SequenceReservation
+	released     map[uint64]struct{} // gaps below next to reuse
+}
+
+// SequenceReservation reserved sequence of built tx
+type SequenceReservation struct {
+	Address     string    `json:"address"`
+	Sequence    uint64    `json:"sequence"`
+	Identifier  string    `json:"identifier"`
+	TxHash      string    `json:"txhash,omitempty"`
+	ReserveTime time.Time `json:"reserveTime"`
+	SendTime    time.Time `json:"sendTime,omitempty"`
+}
+
+// IsPending the tx of the reservation is sent
+func (r *SequenceReservation) IsPending() bool {
+	return r.TxHash != ""
+}
+
+// NewSequenceManager new sequence manager
+func NewSequenceManager() *SequenceManager {
+	return &SequenceManager{
+		accounts: make(map[string]*accountSequences),
+	}
+}
+
+func (m *SequenceManager) getAccount(address string) *accountSequences {
+	key := strings.ToLower(address)
+	acc, exist := m.accounts[key]
+	if !exist {
+		acc = &accountSequences{
+			reservations: make(map[uint64]*SequenceReservation),
+			released:     make(map[uint64]struct{}),
+		}
+		m.accounts[key] = acc
+	}
+	return acc
+}
+
+// Reserve reserve sequence for building tx of identifier.
+// chainSeq is the sequence queried from chain, minSeq is the lower bound of
+// new allocated sequence (eg. adjusted by the nonce of sent txs), it only
+// raises the next sequence and is not taken as sequence on chain.
+// the same sequence is returned if the identifier has an unsent reservation,
+// released gaps are reused before allocating new sequence.
+func (m *SequenceManager) Reserve(address, identifier string, chainSeq, minSeq uint64) uint64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc := m.getAccount(address)
+	acc.reconcile(address, chainSeq)
+	if acc.next < minSeq {
+		acc.next = minSeq
+	}
+
+	for seq, r := range acc.reservations {
+		if r.Identifier == identifier && !r.IsPending() {
+			r.ReserveTime = time.Now()
+			log.Info("reuse reserved sequence", "address", address, "sequence", seq, "identifier", identifier)
+			return seq
+		}
+	}
+
+	seq := acc.next
+	if len(acc.released) > 0 {
+		gaps := make([]uint64, 0, len(acc.released))
+		for gap := range acc.released {
+			gaps = append(gaps, gap)
+		}
+		sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+		seq = gaps[0]
+		delete(acc.released, seq)
+	} else {
+		acc.next++
+	}
+	acc.reservations[seq] = &SequenceReservation{
+		Address:     address,
+		Sequence:    seq,
+		Identifier:  identifier,
+		ReserveTime: time.Now(),
+	}
+	log.Info("reserve sequence", "address", address, "sequence", seq, "identifier", identifier, "chainSeq", acc.chainSeq)
+	return seq
+}
+
+// MarkPending record tx hash of sent tx
+func (m *SequenceManager) MarkPending(address string, sequence uint64, txHash string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc := m.getAccount(address)
+	r, exist := acc.reservations[sequence]
+	if !exist {
+		if sequence < acc.chainSeq {
+			return
+		}
+		// sent tx is not built by us (eg. signed with specified sequence)
+		r = &SequenceReservation{Address: address, Sequence: sequence, ReserveTime: time.Now()}
+		acc.reservations[sequence] = r
+		delete(acc.released, sequence)
+		if sequence >= acc.next {
+			acc.next = sequence + 1
+		}
+	}
+	r.TxHash = txHash
+	r.SendTime = time.Now()
+}
+
+// Release release reserved sequence of abandoned tx, the sequence will be reused
+func (m *SequenceManager) Release(address string, sequence uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc := m.getAccount(address)
+	if _, exist := acc.reservations[sequence]; !exist {
+		return
+	}
+	acc.release(address, sequence)
+}
+
+// Reconcile reconcile with the latest sequence on chain
+func (m *SequenceManager) Reconcile(address string, chainSeq uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.getAccount(address).reconcile(address, chainSeq)
+}
+
+// TrackedAddresses addresses which have reservations
+func (m *SequenceManager) TrackedAddresses() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var addresses []string
+	for _, acc := range m.accounts {
+		for _, r := range acc.reservations {
+			addresses = append(addresses, r.Address)
+			break
+		}
+	}
+	return addresses
+}
+
+// Reservations get copy of reservations of address sorted by sequence
+func (m *SequenceManager) Reservations(address string) []*SequenceReservation {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc := m.getAccount(address)
+	result := make([]*SequenceReservation, 0, len(acc.reservations))
+	for _, r := range acc.reservations {
+		rCopy := *r
+		result = append(result, &rCopy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Sequence < result[j].Sequence })
+	return result
+}
+
+// reconcile removes reservations included in block (sequence below chain sequence),
+// and releases expired reservations of abandoned or dropped txs.
+func (acc *accountSequences) reconcile(address string, chainSeq uint64) {
+	if chainSeq < acc.chainSeq {
+		log.Warn("ignore lower chain sequence", "address", address, "have", chainSeq, "latest", acc.chainSeq)
+		chainSeq = acc.chainSeq
+	}
+	acc.chainSeq = chainSeq
+	if acc.next < chainSeq {
+		acc.next = chainSeq
+	}
+	for seq, r := range acc.reservations {
+		if seq < chainSeq {
+			log.Info("reserved sequence is used on chain", "address", address, "sequence", seq, "identifier", r.Identifier, "txhash", r.TxHash)
+			delete(acc.reservations, seq)
+		}
+	}
+	for gap := range acc.released {
+		if gap < chainSeq {
+			delete(acc.released, gap)
+		}
+	}
+	now := time.Now()
+	for seq, r := range acc.reservations {
+		switch {
+		case r.IsPending() && now.Sub(r.SendTime) > PendingTxTimeout:
+			log.Warn("pending tx is not included in time", "address", address, "sequence", seq, "txhash", r.TxHash)
+		case !r.IsPending() && now.Sub(r.ReserveTime) > SequenceReservationTimeout:
+			log.Warn("reserved sequence is not used in time", "address", address, "sequence", seq, "identifier", r.Identifier)
+		default:
+			continue
+		}
+		acc.release(address, seq)
+	}
+}
+
+func (acc *accountSequences) release(address string, sequence uint64) {
+	delete(acc.reservations, sequence)
+	acc.released[sequence] = struct{}{}
+	// shrink trailing gaps
+	for acc.next > acc.chainSeq {
+		if _, exist := acc.released[acc.next-1]; !exist {
+			break
+		}
+		acc.next--
+		delete(acc.released, acc.next)
+	}
+	log.Info("release sequence", "address", address, "sequence", sequence, "next", acc.next)
+}
+
+// StartSequenceReconciler reconcile sequences of tracked addresses on each new block
+func StartSequenceReconciler(b *Bridge) {
+	utils.TopWaitGroup.Add(1)
+	go b.runSequenceReconciler()
+}
+
+func (b *Bridge) runSequenceReconciler() {
+	defer utils.TopWaitGroup.Done()
+
+	var lastHeight uint64
+	for !utils.IsCleanuping() {
+		if height, err := b.GetLatestBlockNumber(); err == nil && height > lastHeight {
+			lastHeight = height
+			for _, address := range b.SequenceManager.TrackedAddresses() {
+				_, chainSeq, err := b.loadAccountState(baseCtx, address)
+				if err != nil {
+					log.Warn("reconcile sequence get account failed", "address", address, "err", err)
+					continue
+				}
+				b.SequenceManager.Reconcile(address, chainSeq)
+			}
+		}
+		select {
+		case <-utils.CleanupChan:
+			return
+		case <-time.After(SequenceReconcileInterval):
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+)
+
+const testAddress = "inj1test"
+
+func checkReservations(t *testing.T, m *SequenceManager, want ...uint64) {
+	t.Helper()
+	reservations := m.Reservations(testAddress)
+	if len(reservations) != len(want) {
+		t.Fatalf("reservations count mismatch, have %v want %v", len(reservations), len(want))
+	}
+	for i, r := range reservations {
+		if r.Sequence != want[i] {
+			t.Fatalf("reservation sequence mismatch, have %v want %v", r.Sequence, want[i])
+		}
+	}
+}
+
+func TestSequenceReserve(t *testing.T) {
+	m := NewSequenceManager()
+	if seq := m.Reserve(testAddress, "swap1", 5, 5); seq != 5 {
+		t.Fatalf("reserve first sequence, have %v want 5", seq)
+	}
+	if seq := m.Reserve(testAddress, "swap2", 5, 5); seq != 6 {
+		t.Fatalf("reserve next sequence, have %v want 6", seq)
+	}
+	if seq := m.Reserve(testAddress, "swap1", 5, 5); seq != 5 {
+		t.Fatalf("reserve again of unsent swap, have %v want 5", seq)
+	}
+	m.MarkPending(testAddress, 5, "hash1")
+	if seq := m.Reserve(testAddress, "swap1", 5, 5); seq != 7 {
+		t.Fatalf("reserve again of sent swap, have %v want 7", seq)
+	}
+	checkReservations(t, m, 5, 6, 7)
+}
+
+func TestSequenceReserveMinSeq(t *testing.T) {
+	m := NewSequenceManager()
+	m.Reserve(testAddress, "swap1", 5, 5)
+	m.MarkPending(testAddress, 5, "hash1")
+
+	// min sequence raises the next sequence only
+	if seq := m.Reserve(testAddress, "swap2", 5, 8); seq != 8 {
+		t.Fatalf("reserve with min sequence, have %v want 8", seq)
+	}
+	checkReservations(t, m, 5, 8)
+
+	// lower min sequence does not lower the next sequence
+	if seq := m.Reserve(testAddress, "swap3", 5, 6); seq != 9 {
+		t.Fatalf("reserve with lower min sequence, have %v want 9", seq)
+	}
+	checkReservations(t, m, 5, 8, 9)
+}
+
+func TestSequenceRelease(t *testing.T) {
+	m := NewSequenceManager()
+	m.Reserve(testAddress, "swap1", 5, 5)
+	m.Reserve(testAddress, "swap2", 5, 5)
+	m.Reserve(testAddress, "swap3", 5, 5)
+
+	m.Release(testAddress, 6)
+	checkReservations(t, m, 5, 7)
+	if seq := m.Reserve(testAddress, "swap4", 5, 5); seq != 6 {
+		t.Fatalf("reuse released gap, have %v want 6", seq)
+	}
+
+	// trailing released sequences are not kept as gaps
+	m.Release(testAddress, 7)
+	m.Release(testAddress, 6)
+	checkReservations(t, m, 5)
+	if seq := m.Reserve(testAddress, "swap5", 5, 5); seq != 6 {
+		t.Fatalf("reserve after shrink, have %v want 6", seq)
+	}
+	if seq := m.Reserve(testAddress, "swap6", 5, 5); seq != 7 {
+		t.Fatalf("reserve after shrink, have %v want 7", seq)
+	}
+
+	// release not reserved sequence is ignored
+	m.Release(testAddress, 100)
+	checkReservations(t, m, 5, 6, 7)
+}
+
+func TestSequenceReconcile(t *testing.T) {
+	m := NewSequenceManager()
+	m.Reserve(testAddress, "swap1", 5, 5)
+	m.Reserve(testAddress, "swap2", 5, 5)
+	m.Reserve(testAddress, "swap3", 5, 5)
+	m.MarkPending(testAddress, 5, "hash1")
+	m.MarkPending(testAddress, 6, "hash2")
+
+	m.Reconcile(testAddress, 6)
+	checkReservations(t, m, 6, 7)
+
+	// lower chain sequence (eg. stale cache) is ignored
+	m.Reconcile(testAddress, 4)
+	checkReservations(t, m, 6, 7)
+	if seq := m.Reserve(testAddress, "swap4", 4, 4); seq != 8 {
+		t.Fatalf("reserve after lower chain sequence, have %v want 8", seq)
+	}
+
+	// chain sequence beyond all reservations
+	m.Reconcile(testAddress, 10)
+	checkReservations(t, m)
+	if seq := m.Reserve(testAddress, "swap5", 10, 10); seq != 10 {
+		t.Fatalf("reserve after reconcile, have %v want 10", seq)
+	}
+}
+
+func TestSequenceReconcileTimeout(t *testing.T) {
+	backupReserve, backupPending := SequenceReservationTimeout, PendingTxTimeout
+	defer func() {
+		SequenceReservationTimeout, PendingTxTimeout = backupReserve, backupPending
+	}()
+	SequenceReservationTimeout, PendingTxTimeout = time.Hour, time.Hour
+
+	m := NewSequenceManager()
+	m.Reserve(testAddress, "swap1", 5, 5)
+	m.Reserve(testAddress, "swap2", 5, 5)
+	m.Reserve(testAddress, "swap3", 5, 5)
+	m.MarkPending(testAddress, 5, "hash1")
+
+	// pending tx is dropped
+	PendingTxTimeout = 0
+	m.Reconcile(testAddress, 5)
+	checkReservations(t, m, 6, 7)
+	if seq := m.Reserve(testAddress, "swap4", 5, 5); seq != 5 {
+		t.Fatalf("reuse sequence of dropped tx, have %v want 5", seq)
+	}
+
+	// unsent reservations are abandoned
+	PendingTxTimeout = time.Hour
+	SequenceReservationTimeout = 0
+	m.Reconcile(testAddress, 5)
+	checkReservations(t, m)
+	SequenceReservationTimeout = time.Hour
+	if seq := m.Reserve(testAddress, "swap5", 5, 5); seq != 5 {
+		t.Fatalf("reserve after abandoned, have %v want 5", seq)
+	}
+}
@@ -1,9 +1,11 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -12,11 +14,16 @@ import (
 	"github.com/anyswap/CrossChain-Router/v3/router"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
 	"github.com/anyswap/CrossChain-Router/v3/tools/crypto"
-	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/anyswap/RouterSDK-injective/metrics"
 )
 
 // MPCSignTransaction mpc sign raw tx
 func (b *Bridge) MPCSignTransaction(rawTx interface{}, args *tokens.BuildTxArgs) (signedTx interface{}, txHash string, err error) {
+	return b.MPCSignTransactionWithContext(baseCtx, rawTx, args)
+}
+
+// MPCSignTransactionWithContext mpc sign raw tx with context
+func (b *Bridge) MPCSignTransactionWithContext(ctx context.Context, rawTx interface{}, args *tokens.BuildTxArgs) (signedTx interface{}, txHash string, err error) {
 	if buildRawTx, ok := rawTx.(*BuildRawTx); !ok {
 		return nil, "", errors.New("wrong raw tx param")
 	} else {
@@ -27,18 +34,18 @@ func (b *Bridge) MPCSignTransaction(rawTx interface{}, args *tokens.BuildTxArgs)
 		mpcParams := params.GetMPCConfig(b.UseFastMPC)
 		if mpcParams.SignWithPrivateKey {
 			priKey := mpcParams.GetSignerPrivateKey(b.ChainConfig.ChainID)
-			return b.SignTransactionWithPrivateKey(buildRawTx, priKey)
+			return b.signTransactionWithPrivateKey(ctx, buildRawTx, priKey)
 		}
 
 		mpcPubkey := router.GetMPCPublicKey(args.From)
 		if mpcPubkey == "" {
 			return nil, "", tokens.ErrMissMPCPublicKey
 		}
-		pubKey, err := PubKeyFromStr(mpcPubkey)
+		pubKey, err := b.PubKeyFromStr(mpcPubkey)
 		if err != nil {
 			return nil, "", err
 		}
-		if signBytes, err := b.GetSignBytes(buildRawTx); err != nil {
+		if signBytes, err := b.GetSignBytesWithContext(ctx, buildRawTx); err != nil {
 			return nil, "", err
 		} else {
 			jsondata, _ := json.Marshal(args.GetExtraArgs())
@@ -49,8 +56,11 @@ func (b *Bridge) MPCSignTransaction(rawTx interface{}, args *tokens.BuildTxArgs)
 			log.Info(logPrefix+"start", "txid", txid)
 
 			mpcConfig := mpc.GetMPCConfig(b.UseFastMPC)
-			msgHash := fmt.Sprintf("%X", Sha256Sum(signBytes))
-			if keyID, rsvs, err := mpcConfig.DoSignOneEC(mpcPubkey, msgHash, msgContext); err != nil {
+			msgHash := fmt.Sprintf("%X", b.KeyType.SignHash(signBytes))
+			signStart := time.Now()
+			keyID, rsvs, err := mpcConfig.DoSignOneEC(mpcPubkey, msgHash, msgContext)
+			metrics.ObserveMPCSign(time.Since(signStart), err)
+			if err != nil {
 				return nil, "", err
 			} else {
 				if len(rsvs) != 1 {
@@ -91,12 +101,19 @@ func (b *Bridge) MPCSignTransaction(rawTx interface{}, args *tokens.BuildTxArgs)
 
 // SignTransactionWithPrivateKey sign tx with ECDSA private key
 func (b *Bridge) SignTransactionWithPrivateKey(buildRawTx *BuildRawTx, privKey string) (signedTx interface{}, txHash string, err error) {
+	return b.signTransactionWithPrivateKey(baseCtx, buildRawTx, privKey)
+}
+
+func (b *Bridge) signTransactionWithPrivateKey(ctx context.Context, buildRawTx *BuildRawTx, privKey string) (signedTx interface{}, txHash string, err error) {
 	if ecPrikey, err := crypto.HexToECDSA(privKey); err != nil {
 		return nil, "", err
 	} else {
-		ecPriv := &secp256k1.PrivKey{Key: ecPrikey.D.Bytes()}
+		ecPriv, err := b.KeyType.PrivKeyFromBytes(crypto.FromECDSA(ecPrikey))
+		if err != nil {
+			return nil, "", err
+		}
 
-		if signBytes, err := b.GetSignBytes(buildRawTx); err != nil {
+		if signBytes, err := b.GetSignBytesWithContext(ctx, buildRawTx); err != nil {
 			return nil, "", err
 		} else {
 			if signature, err := ecPriv.Sign(signBytes); err != nil {
@@ -7,7 +7,6 @@ import (
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
 	"github.com/anyswap/CrossChain-Router/v3/router"
-	"github.com/anyswap/CrossChain-Router/v3/tools"
 	"github.com/anyswap/RouterSDK-injective/config"
 )
 
@@ -86,45 +85,18 @@ func InitAfterLoad() {
 	log.Info("init after load finished", "chainID", chainID, "chainName", chainCfg.BlockChain)
 }
 
-// AdjustGatewayOrder adjust gateway order once
+// AdjustGatewayOrder probe gateways once, the available gateways are ordered by lag and latency
 func (b *Bridge) AdjustGatewayOrder() {
-	chainID := config.GetServerConfig().ChainID
-	// use block number as weight
-	var weightedAPIs tools.WeightedStringSlice
-	gateway := b.GetGatewayConfig()
-	if gateway == nil {
+	if utils.IsCleanuping() {
 		return
 	}
-	var maxHeight uint64
-	length := len(gateway.APIAddress)
-	for i := length; i > 0; i-- { // query in reverse order
-		if utils.IsCleanuping() {
-			return
-		}
-		apiAddress := gateway.APIAddress[i-1]
-		height, _ := b.GetLatestBlockNumberOf(apiAddress)
-		weightedAPIs = weightedAPIs.Add(apiAddress, height)
-		if height > maxHeight {
-			maxHeight = height
-		}
-	}
-	if length == 0 { // update for bridges only use grpc apis
-		maxHeight, _ = b.GetLatestBlockNumber()
-	}
-	if maxHeight > 0 {
-		log.Info("update latest block number", "chainID", chainID, "height", maxHeight)
-	}
-	if len(weightedAPIs) > 0 {
-		weightedAPIs.Reverse() // reverse as iter in reverse order in the above
-		weightedAPIs = weightedAPIs.Sort()
-		gateway.APIAddress = weightedAPIs.GetStrings()
-		gateway.WeightedAPIs = weightedAPIs
-	}
+	b.ProbeGateways()
 }
 
 func (b *Bridge) adjustGateway() {
-	for adjustCount := 0; ; adjustCount++ {
-		for i := 0; i < adjustInterval; i++ {
+	lastLogTime := time.Now()
+	for {
+		for i := time.Duration(0); i < GatewayProbeInterval; i += time.Second {
 			if utils.IsCleanuping() {
 				return
 			}
@@ -133,8 +105,9 @@ func (b *Bridge) adjustGateway() {
 
 		b.AdjustGatewayOrder()
 
-		if adjustCount%3 == 0 && b.GetGatewayConfig().WeightedAPIs.Len() > 0 {
-			log.Info("adjust gateways", "result", b.GetGatewayConfig().WeightedAPIs)
+		if time.Since(lastLogTime) >= time.Duration(adjustInterval)*time.Second {
+			lastLogTime = time.Now()
+			log.Info("adjust gateways", "result", common.ToJSONString(GetGatewayStatus(), false))
 		}
 	}
 }
@@ -0,0 +1,265 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	"github.com/anyswap/RouterSDK-injective/config"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+var (
+	// SubscribeIdleTimeout reconnect to the next gateway if no new block in this duration
+	SubscribeIdleTimeout = 30 * time.Second
+	// SubscribeReconnectInterval wait interval after all gateways are tried
+	SubscribeReconnectInterval = 5 * time.Second
+	// LatestHeightCacheTTL the subscribed latest height is used in this duration after received
+	LatestHeightCacheTTL = 10 * time.Second
+
+	maxRecentDeposits      = 100
+	depositChannelCapacity = 1000
+
+	subscriberName = "router-sdk"
+
+	eventSubscriber = &EventSubscriber{
+		deposits: make(chan *DepositNotification, depositChannelCapacity),
+	}
+)
+
+// DepositNotification notification of tx with transfer to router mpc
+type DepositNotification struct {
+	TxHash    string    `json:"txhash"`
+	Height    uint64    `json:"height"`
+	Recipient string    `json:"recipient"`
+	Endpoint  string    `json:"endpoint"`
+	Time      time.Time `json:"time"`
+}
+
+// SubscriptionStatus status of event subscription
+type SubscriptionStatus struct {
+	Enabled      bool      `json:"enabled"`
+	Connected    bool      `json:"connected"`
+	Endpoint     string    `json:"endpoint,omitempty"`
+	LatestHeight uint64    `json:"latestHeight"`
+	LatestTime   time.Time `json:"latestTime,omitempty"`
+	Addresses    []string  `json:"addresses,omitempty"`
+	Reconnects   uint64    `json:"reconnects"`
+	Deposits     uint64    `json:"deposits"`
+	Dropped      uint64    `json:"dropped"` // deposit notifications dropped as the channel is full
+}
+
+// EventSubscriber subscribes new blocks and transfers to router mpc by tendermint websocket
+type EventSubscriber struct {
+	lock   sync.RWMutex
+	status SubscriptionStatus
+
+	deposits       chan *DepositNotification
+	recentDeposits []*DepositNotification
+}
+
+type subscribedEvent struct {
+	recipient string
+	event     ctypes.ResultEvent
+}
+
+// StartEventSubscriber start event subscription if enabled
+func StartEventSubscriber(b *Bridge) {
+	cfg := config.GetServerConfig().SubscribeConfig
+	if cfg == nil || !cfg.Enable {
+		return
+	}
+	eventSubscriber.lock.Lock()
+	eventSubscriber.status.Enabled = true
+	eventSubscriber.lock.Unlock()
+
+	utils.TopWaitGroup.Add(1)
+	go eventSubscriber.run(b)
+}
+
+// DepositNotifications channel of deposit notifications (non blocking, dropped if full)
+func DepositNotifications() <-chan *DepositNotification {
+	return eventSubscriber.deposits
+}
+
+// GetRecentDeposits get recent deposit notifications
+func GetRecentDeposits() []*DepositNotification {
+	s := eventSubscriber
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	result := make([]*DepositNotification, len(s.recentDeposits))
+	copy(result, s.recentDeposits)
+	return result
+}
+
+// GetSubscriptionStatus get status of event subscription
+func GetSubscriptionStatus() *SubscriptionStatus {
+	s := eventSubscriber
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	status := s.status
+	return &status
+}
+
+// cachedLatestHeight get the subscribed latest height if it's fresh
+func cachedLatestHeight() (uint64, bool) {
+	s := eventSubscriber
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if !s.status.Connected || s.status.LatestHeight == 0 || time.Since(s.status.LatestTime) > LatestHeightCacheTTL {
+		return 0, false
+	}
+	return s.status.LatestHeight, true
+}
+
+func (s *EventSubscriber) run(b *Bridge) {
+	defer utils.TopWaitGroup.Done()
+
+	for !utils.IsCleanuping() {
+		for _, url := range grpcEndpoints() {
+			if utils.IsCleanuping() {
+				return
+			}
+			err := s.subscribe(b, url)
+			s.lock.Lock()
+			s.status.Connected = false
+			s.status.Reconnects++
+			s.lock.Unlock()
+			if err != nil {
+				log.Warn("event subscription disconnected", "url", url, "err", err)
+			}
+		}
+		select {
+		case <-utils.CleanupChan:
+			return
+		case <-time.After(SubscribeReconnectInterval):
+		}
+	}
+}
+
+// subscribe subscribes events from the gateway of url until disconnected, idle or shutdown.
+// a new client is created for each connection as a stopped client can not be restarted.
+func (s *EventSubscriber) subscribe(b *Bridge, url string) error {
+	client, err := rpchttp.New(url, "/websocket")
+	if err != nil {
+		return err
+	}
+	if err = client.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Stop()
+	}()
+
+	ctx, cancel := context.WithCancel(baseCtx)
+	defer cancel()
+
+	blocks, err := client.Subscribe(ctx, subscriberName, tmtypes.EventQueryNewBlock.String())
+	if err != nil {
+		return err
+	}
+
+	mpcs := b.getRouterMPCs()
+	addresses := make([]string, 0, len(mpcs))
+	for mpc := range mpcs {
+		addresses = append(addresses, mpc)
+	}
+	sort.Strings(addresses)
+
+	// the query language does not support `OR`, subscribe each address separately
+	txs := make(chan subscribedEvent)
+	for _, address := range addresses {
+		query := fmt.Sprintf("%s AND %s.recipient='%s'", tmtypes.EventQueryTx.String(), TransferType, address)
+		out, errs := client.Subscribe(ctx, subscriberName, query)
+		if errs != nil {
+			return errs
+		}
+		go func(recipient string, out <-chan ctypes.ResultEvent) {
+			for event := range out {
+				select {
+				case txs <- subscribedEvent{recipient: recipient, event: event}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(address, out)
+	}
+
+	s.lock.Lock()
+	s.status.Connected = true
+	s.status.Endpoint = url
+	s.status.Addresses = addresses
+	s.lock.Unlock()
+	log.Info("event subscription connected", "url", url, "addresses", addresses)
+
+	idle := time.NewTimer(SubscribeIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case <-utils.CleanupChan:
+			return nil
+		case <-idle.C:
+			return fmt.Errorf("no new block in %v", SubscribeIdleTimeout)
+		case event, ok := <-blocks:
+			if !ok {
+				return fmt.Errorf("new block subscription closed")
+			}
+			if data, ok := event.Data.(tmtypes.EventDataNewBlock); ok && data.Block != nil {
+				s.onNewBlock(uint64(data.Block.Height))
+				if !idle.Stop() {
+					<-idle.C
+				}
+				idle.Reset(SubscribeIdleTimeout)
+			}
+		case tx := <-txs:
+			if data, ok := tx.event.Data.(tmtypes.EventDataTx); ok && data.Result.Code == 0 {
+				s.onDeposit(&DepositNotification{
+					TxHash:    fmt.Sprintf("%X", tmtypes.Tx(data.Tx).Hash()),
+					Height:    uint64(data.Height),
+					Recipient: tx.recipient,
+					Endpoint:  url,
+					Time:      time.Now(),
+				})
+			}
+		}
+	}
+}
+
+func (s *EventSubscriber) onNewBlock(height uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if height > s.status.LatestHeight {
+		s.status.LatestHeight = height
+		observeLatestHeight(height)
+	}
+	s.status.LatestTime = time.Now()
+}
+
+func (s *EventSubscriber) onDeposit(deposit *DepositNotification) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// a tx may transfer to several mpcs, notify once
+	for _, recent := range s.recentDeposits {
+		if recent.TxHash == deposit.TxHash {
+			return
+		}
+	}
+	s.recentDeposits = append(s.recentDeposits, deposit)
+	if len(s.recentDeposits) > maxRecentDeposits {
+		s.recentDeposits = s.recentDeposits[1:]
+	}
+	s.status.Deposits++
+	select {
+	case s.deposits <- deposit:
+	default:
+		s.status.Dropped++
+		log.Warn("deposit notification dropped", "txhash", deposit.TxHash, "height", deposit.Height)
+	}
+	log.Info("subscribed deposit tx", "txhash", deposit.TxHash, "height", deposit.Height, "recipient", deposit.Recipient)
+}
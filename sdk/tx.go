@@ -1,11 +1,11 @@
 package sdk
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"math/big"
-	"strings"
 
 	tokenfactoryTypes "github.com/InjectiveLabs/sdk-go/chain/tokenfactory/types"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -65,6 +65,7 @@ func BuildSignatures(publicKey cryptoTypes.PubKey, sequence uint64, signature []
 }
 
 func (b *Bridge) BuildTx(
+	ctx context.Context,
 	args *tokens.BuildTxArgs,
 	to, denom, memo, publicKey string,
 	amount *big.Int,
@@ -72,15 +73,18 @@ func (b *Bridge) BuildTx(
 	from := args.From
 	extra := args.Extra
 	log.Info("start to build tx", "swapID", args.SwapID, "from", from, "to", to, "denom", denom, "memo", memo, "amount", amount, "fee", *extra.Fee, "gas", *extra.Gas, "sequence", *extra.Sequence)
-	if balance, err := b.GetDenomBalance(from, denom); err != nil {
+	if balance, err := b.GetDenomBalance(ctx, from, denom); err != nil {
 		return nil, err
 	} else {
 		var msgs []sdk.Msg
 		if balance.BigInt().Cmp(amount) >= 0 {
-			sendMsg := BuildSendMsg(from, to, denom, amount)
+			sendMsg, err := b.buildDeliverMsg(ctx, args, from, to, denom, amount)
+			if err != nil {
+				return nil, err
+			}
 			msgs = append(msgs, sendMsg)
 
-			if strings.Contains(denom, "/") && balance.BigInt().Cmp(amount) > 0 {
+			if IsTokenFactoryDenom(denom) && balance.BigInt().Cmp(amount) > 0 {
 				creator, _, errt := tokenfactoryTypes.DeconstructDenom(denom)
 				if errt != nil {
 					return nil, errt
@@ -93,19 +97,23 @@ func (b *Bridge) BuildTx(
 				}
 			}
 		} else {
-			if strings.Contains(denom, "/") {
+			if IsTokenFactoryDenom(denom) {
 				creator, _, errt := tokenfactoryTypes.DeconstructDenom(denom)
 				if errt != nil {
 					return nil, errt
 				}
 				if creator == from {
-					sendMsg := BuildSendMsg(from, to, denom, balance.BigInt())
-					msgs = append(msgs, sendMsg)
-
+					// mint the shortage first, then deliver the full amount
 					mintAmount := new(big.Int).Sub(amount, balance.BigInt())
 					coin := sdk.NewCoin(denom, sdk.NewIntFromBigInt(mintAmount))
 					mintMsg := BuildMintMsg(from, coin)
 					msgs = append(msgs, mintMsg)
+
+					sendMsg, err := b.buildDeliverMsg(ctx, args, from, to, denom, amount)
+					if err != nil {
+						return nil, err
+					}
+					msgs = append(msgs, sendMsg)
 				} else {
 					log.Info("balance not enough", "denom", denom, "balance", balance, "amount", amount)
 					return nil, tokens.ErrBalanceNotEnough
@@ -117,18 +125,10 @@ func (b *Bridge) BuildTx(
 		}
 
 		// process charge fee on dest chain
-		var bridgeFeeReceiver string
-		tokenID := args.GetTokenID()
-		fromChainID := args.FromChainID
-		toChainID := args.ToChainID
-		if params.ChargeFeeOnDestChain(tokenID, fromChainID.String(), toChainID.String()) {
-			if extra.BridgeFee != nil && extra.BridgeFee.Sign() > 0 {
-				bridgeFeeReceiver = params.FeeReceiverOnDestChain(toChainID.String())
-			}
-		}
+		bridgeFeeReceiver := getBridgeFeeReceiver(args)
 		if bridgeFeeReceiver != "" {
 			var isMinted bool
-			if strings.Contains(denom, "/") {
+			if IsTokenFactoryDenom(denom) {
 				creator, _, errt := tokenfactoryTypes.DeconstructDenom(denom)
 				if errt != nil {
 					return nil, errt
@@ -151,36 +151,60 @@ func (b *Bridge) BuildTx(
 			log.Info("build charge fee on dest chain", "swapID", args.SwapID, "from", from, "receiver", bridgeFeeReceiver, "denom", denom, "fee", extra.BridgeFee)
 		}
 
-		txBuilder := b.TxConfig.NewTxBuilder()
-		if err := txBuilder.SetMsgs(msgs...); err != nil {
-			return nil, err
-		}
-		txBuilder.SetMemo(memo)
-		if fee, err := ParseCoinsFee(*extra.Fee); err != nil {
-			return nil, err
-		} else {
-			txBuilder.SetFeeAmount(fee)
-		}
-		txBuilder.SetGasLimit(*extra.Gas)
-		pubKey, err := PubKeyFromStr(publicKey)
-		if err != nil {
-			return nil, err
-		}
-		sig := BuildSignatures(pubKey, *extra.Sequence, nil)
-		if err := txBuilder.SetSignatures(sig); err != nil {
-			return nil, err
-		}
-		if err := txBuilder.GetTx().ValidateBasic(); err != nil {
-			return nil, err
+		return b.newTxBuilder(msgs, memo, publicKey, extra)
+	}
+}
+
+// getBridgeFeeReceiver get fee receiver if charge fee on dest chain
+func getBridgeFeeReceiver(args *tokens.BuildTxArgs) string {
+	tokenID := args.GetTokenID()
+	fromChainID := args.FromChainID
+	toChainID := args.ToChainID
+	extra := args.Extra
+	if params.ChargeFeeOnDestChain(tokenID, fromChainID.String(), toChainID.String()) {
+		if extra.BridgeFee != nil && extra.BridgeFee.Sign() > 0 {
+			return params.FeeReceiverOnDestChain(toChainID.String())
 		}
+	}
+	return ""
+}
 
-		return txBuilder, nil
+// newTxBuilder new tx builder with empty signature
+func (b *Bridge) newTxBuilder(msgs []sdk.Msg, memo, publicKey string, extra *tokens.AllExtras) (cosmosClient.TxBuilder, error) {
+	txBuilder := b.TxConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(msgs...); err != nil {
+		return nil, err
+	}
+	txBuilder.SetMemo(memo)
+	if fee, err := ParseCoinsFee(*extra.Fee); err != nil {
+		return nil, err
+	} else {
+		txBuilder.SetFeeAmount(fee)
+	}
+	txBuilder.SetGasLimit(*extra.Gas)
+	pubKey, err := b.PubKeyFromStr(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	sig := BuildSignatures(pubKey, *extra.Sequence, nil)
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return nil, err
+	}
+	if err := txBuilder.GetTx().ValidateBasic(); err != nil {
+		return nil, err
 	}
+
+	return txBuilder, nil
 }
 
 func (b *Bridge) GetSignBytes(tx *BuildRawTx) ([]byte, error) {
+	return b.GetSignBytesWithContext(baseCtx, tx)
+}
+
+// GetSignBytesWithContext get sign bytes with context
+func (b *Bridge) GetSignBytesWithContext(ctx context.Context, tx *BuildRawTx) ([]byte, error) {
 	handler := b.TxConfig.SignModeHandler()
-	if chainName, err := b.GetChainID(); err != nil {
+	if chainName, err := b.GetChainID(ctx); err != nil {
 		return nil, err
 	} else {
 		txBuilder := tx.TxBuilder
@@ -1,9 +1,12 @@
 package sdk
 
 import (
+	"strconv"
+
 	"github.com/anyswap/CrossChain-Router/v3/common/hexutil"
 	cosmosClient "github.com/cosmos/cosmos-sdk/client"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
 )
 
 type BuildRawTx struct {
@@ -11,6 +14,7 @@ type BuildRawTx struct {
 	EncodedTx     hexutil.Bytes          `json:"encoded_tx,omitempty"`
 	AccountNumber uint64                 `json:"account_number,omitempty"`
 	Sequence      uint64                 `json:"sequence,omitempty"`
+	BatchSwaps    []*BatchSwap           `json:"batch_swaps,omitempty"`
 }
 
 // GetLatestBlockResponse is the response type for the Query/GetLatestBlock RPC
@@ -21,7 +25,20 @@ type GetLatestBlockResponse struct {
 }
 
 type Block struct {
-	Header Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header"`
+	Header Header    `protobuf:"bytes,1,opt,name=header,proto3" json:"header"`
+	Data   BlockData `protobuf:"bytes,2,opt,name=data,proto3" json:"data"`
+}
+
+// BlockData txs (base64 encoded) of block
+type BlockData struct {
+	Txs []string `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+}
+
+// BlockTx tx result in block
+type BlockTx struct {
+	Hash   string
+	Code   uint32
+	Events []Event
 }
 
 // Header defines the structure of a Tendermint block header.
@@ -45,10 +62,20 @@ type TxResponse struct {
 	Height string `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
 	// The transaction hash.
 	TxHash string `protobuf:"bytes,2,opt,name=txhash,proto3" json:"txhash,omitempty"`
+	// Namespace for the Code
+	Codespace string `protobuf:"bytes,3,opt,name=codespace,proto3" json:"codespace,omitempty"`
 	// Response code.
 	Code uint32 `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	// The output of the application's logger (raw string). May be non-deterministic.
+	RawLog string `protobuf:"bytes,6,opt,name=raw_log,json=rawLog,proto3" json:"raw_log,omitempty"`
+	// Amount of gas requested for transaction.
+	GasWanted string `protobuf:"varint,9,opt,name=gas_wanted,json=gasWanted,proto3" json:"gas_wanted,omitempty"`
+	// Amount of gas consumed by transaction.
+	GasUsed string `protobuf:"varint,10,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
 	// The output of the application's logger (typed). May be non-deterministic.
 	Logs sdk.ABCIMessageLogs `protobuf:"bytes,7,rep,name=logs,proto3,castrepeated=ABCIMessageLogs" json:"logs"`
+	// Events defines all the events emitted by processing a transaction.
+	Events []Event `protobuf:"bytes,13,rep,name=events,proto3" json:"events,omitempty"`
 }
 
 // Tx tx
@@ -109,3 +136,42 @@ type QueryAllBalancesResponse struct {
 	// balances is the balances of all the coins.
 	Balances sdk.Coins `protobuf:"bytes,1,rep,name=balances,proto3,castrepeated=github.com/cosmos/cosmos-sdk/types.Coins" json:"balances"`
 }
+
+// QueryConfigResponse is the response type for the node Query/Config RPC method.
+type QueryConfigResponse struct {
+	MinimumGasPrice string `protobuf:"bytes,1,opt,name=minimum_gas_price,json=minimumGasPrice,proto3" json:"minimum_gas_price,omitempty"`
+}
+
+// QueryChannelClientStateResponse is the response type for the ibc channel Query/ChannelClientState RPC method.
+type QueryChannelClientStateResponse struct {
+	IdentifiedClientState *IdentifiedClientState `json:"identified_client_state,omitempty"`
+}
+
+type IdentifiedClientState struct {
+	ClientID    string       `json:"client_id,omitempty"`
+	ClientState *ClientState `json:"client_state,omitempty"`
+}
+
+// ClientState light client state (only the latest height is used)
+type ClientState struct {
+	LatestHeight Height `json:"latest_height"`
+}
+
+// Height ibc height, numbers are strings in rest api
+type Height struct {
+	RevisionNumber string `json:"revision_number"`
+	RevisionHeight string `json:"revision_height"`
+}
+
+// ToHeight convert to ibc client height
+func (h Height) ToHeight() (clienttypes.Height, error) {
+	revisionNumber, err := strconv.ParseUint(h.RevisionNumber, 10, 64)
+	if err != nil {
+		return clienttypes.ZeroHeight(), err
+	}
+	revisionHeight, err := strconv.ParseUint(h.RevisionHeight, 10, 64)
+	if err != nil {
+		return clienttypes.ZeroHeight(), err
+	}
+	return clienttypes.NewHeight(revisionNumber, revisionHeight), nil
+}
@@ -1,9 +1,16 @@
 package sdk
 
 import (
+	"strings"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// IsTokenFactoryDenom is denom created by tokenfactory module (`factory/{creator}/{subdenom}`)
+func IsTokenFactoryDenom(denom string) bool {
+	return strings.HasPrefix(denom, "factory/")
+}
+
 func ParseCoinsNormalized(coinStr string) (sdk.Coins, error) {
 	return sdk.ParseCoinsNormalized(coinStr)
 }
@@ -1,6 +1,8 @@
 package sdk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -19,6 +21,11 @@ const (
 
 // VerifyMsgHash verify msg hash
 func (b *Bridge) VerifyMsgHash(tx interface{}, msgHashes []string) (err error) {
+	return b.VerifyMsgHashWithContext(baseCtx, tx, msgHashes)
+}
+
+// VerifyMsgHashWithContext verify msg hash with context
+func (b *Bridge) VerifyMsgHashWithContext(ctx context.Context, tx interface{}, msgHashes []string) (err error) {
 	if len(msgHashes) < 1 {
 		return tokens.ErrWrongCountOfMsgHashes
 	}
@@ -29,10 +36,10 @@ func (b *Bridge) VerifyMsgHash(tx interface{}, msgHashes []string) (err error) {
 		if err != nil {
 			return err
 		}
-		if signBytes, err := b.GetSignBytes(rawTx); err != nil {
+		if signBytes, err := b.GetSignBytesWithContext(ctx, rawTx); err != nil {
 			return err
 		} else {
-			msgHash := fmt.Sprintf("%X", Sha256Sum(signBytes))
+			msgHash := fmt.Sprintf("%X", b.KeyType.SignHash(signBytes))
 			if !strings.EqualFold(msgHash, msgHashes[0]) {
 				log.Warn("message hash mismatch",
 					"want", msgHashes[0], "have", msgHash)
@@ -45,48 +52,72 @@ func (b *Bridge) VerifyMsgHash(tx interface{}, msgHashes []string) (err error) {
 
 // VerifyTransaction impl
 func (b *Bridge) VerifyTransaction(txHash string, args *tokens.VerifyArgs) (*tokens.SwapTxInfo, error) {
+	return b.VerifyTransactionWithContext(baseCtx, txHash, args)
+}
+
+// VerifyTransactionWithContext verify tx with context
+func (b *Bridge) VerifyTransactionWithContext(ctx context.Context, txHash string, args *tokens.VerifyArgs) (*tokens.SwapTxInfo, error) {
+	swapInfo, err := b.VerifyTransactionWithExtra(ctx, txHash, args)
+	if swapInfo == nil {
+		return nil, err
+	}
+	return swapInfo.SwapTxInfo, err
+}
+
+// VerifyTransactionWithExtra verify tx with context, and returns the json swap memo extra of the verified swap
+func (b *Bridge) VerifyTransactionWithExtra(ctx context.Context, txHash string, args *tokens.VerifyArgs) (*SwapTxInfoWithExtra, error) {
 	swapType := args.SwapType
 	logIndex := args.LogIndex
 	allowUnstable := args.AllowUnstable
 
 	switch swapType {
 	case tokens.ERC20SwapType:
-		return b.verifySwapoutTx(txHash, logIndex, allowUnstable)
+		swapInfo, extra, err := b.verifySwapoutTx(ctx, txHash, logIndex, allowUnstable)
+		return &SwapTxInfoWithExtra{SwapTxInfo: swapInfo, Extra: extra}, err
 	default:
 		return nil, tokens.ErrSwapTypeNotSupported
 	}
 }
 
-func (b *Bridge) verifySwapoutTx(txHash string, logIndex int, allowUnstable bool) (*tokens.SwapTxInfo, error) {
+// verifySwapoutTx verify swapout tx, the json swap memo extra is returned only if verified
+func (b *Bridge) verifySwapoutTx(ctx context.Context, txHash string, logIndex int, allowUnstable bool) (*tokens.SwapTxInfo, *SwapMemoExtra, error) {
 	swapInfo := &tokens.SwapTxInfo{SwapInfo: tokens.SwapInfo{ERC20SwapInfo: &tokens.ERC20SwapInfo{}}}
 	swapInfo.SwapType = tokens.ERC20SwapType          // SwapType
 	swapInfo.Hash = txHash                            // Hash
 	swapInfo.LogIndex = logIndex                      // LogIndex
 	swapInfo.FromChainID = b.ChainConfig.GetChainID() // FromChainID
 
-	if txr, err := b.GetTransactionByHash(txHash); err != nil {
+	if txr, err := b.getTransactionForVerify(ctx, txHash); err != nil {
+		if errors.Is(err, ErrQuorumMismatch) {
+			return swapInfo, nil, err
+		}
 		log.Debug("[verifySwapin] "+b.ChainConfig.BlockChain+" Bridge::GetTransaction fail", "tx", txHash, "err", err)
-		return swapInfo, tokens.ErrTxNotFound
+		return swapInfo, nil, tokens.ErrTxNotFound
 	} else {
-		if txHeight, err := b.checkTxStatus(txr, allowUnstable); err != nil {
-			return swapInfo, err
+		if txHeight, err := b.checkTxStatus(ctx, txr, allowUnstable); err != nil {
+			return swapInfo, nil, err
 		} else {
 			swapInfo.Height = txHeight // Height
 		}
 
-		if err := ParseMemo(swapInfo, txr.Tx.Body.Memo); err != nil {
-			return swapInfo, err
-		}
-		if logIndex < 1 || logIndex > len(txr.TxResponse.Logs) {
-			return swapInfo, tokens.ErrLogIndexOutOfRange
+		messageLogs := txr.TxResponse.MessageLogs()
+		if logIndex < 1 || logIndex > len(messageLogs) {
+			return swapInfo, nil, tokens.ErrLogIndexOutOfRange
 		}
 
-		if err := b.ParseAmountTotal(txr.TxResponse.Logs[logIndex-1], swapInfo); err != nil {
-			return swapInfo, err
+		extra, err := b.parseSwapInfo(messageLogs[logIndex-1], txr.Tx.Body.Memo, swapInfo)
+		if err != nil {
+			return swapInfo, nil, err
 		}
 
 		if checkErr := b.checkSwapoutInfo(swapInfo); checkErr != nil {
-			return swapInfo, checkErr
+			return swapInfo, nil, checkErr
+		}
+
+		if lightClientConfig() != nil {
+			if err := b.verifyTxByLightClient(ctx, swapInfo, extra, txr); err != nil {
+				return swapInfo, nil, err
+			}
 		}
 
 		if !allowUnstable {
@@ -96,7 +127,7 @@ func (b *Bridge) verifySwapoutTx(txHash string, logIndex int, allowUnstable bool
 				"height", swapInfo.Height, "timestamp", swapInfo.Timestamp, "logIndex", swapInfo.LogIndex)
 		}
 
-		return swapInfo, nil
+		return swapInfo, extra, nil
 	}
 }
 
@@ -134,14 +165,19 @@ func (b *Bridge) checkSwapoutInfo(swapInfo *tokens.SwapTxInfo) error {
 	}
 
 	bindAddr := swapInfo.Bind
-	if !toBridge.IsValidAddress(bindAddr) {
+	if dstBridge, ok := toBridge.(*Bridge); ok {
+		if !dstBridge.IsValidReceiver(multichainToken, bindAddr) {
+			log.Warn("wrong bind address in dest chain", "bind", bindAddr, "token", multichainToken)
+			return tokens.ErrWrongBindAddress
+		}
+	} else if !toBridge.IsValidAddress(bindAddr) {
 		log.Warn("wrong bind address in dest chain", "bind", bindAddr)
 		return tokens.ErrWrongBindAddress
 	}
 	return nil
 }
 
-func (b *Bridge) checkTxStatus(txres *GetTxResponse, allowUnstable bool) (txHeight uint64, err error) {
+func (b *Bridge) checkTxStatus(ctx context.Context, txres *GetTxResponse, allowUnstable bool) (txHeight uint64, err error) {
 	if txHeight, err := strconv.ParseUint(txres.TxResponse.Height, 10, 64); err != nil {
 		return 0, nil
 	} else {
@@ -150,7 +186,7 @@ func (b *Bridge) checkTxStatus(txres *GetTxResponse, allowUnstable bool) (txHeig
 		}
 
 		if !allowUnstable {
-			if h, err := b.GetLatestBlockNumber(); err != nil {
+			if h, err := b.getLatestBlockNumberForVerify(ctx); err != nil {
 				return txHeight, err
 			} else {
 				if h < txHeight+b.GetChainConfig().Confirmations {
@@ -165,35 +201,11 @@ func (b *Bridge) checkTxStatus(txres *GetTxResponse, allowUnstable bool) (txHeig
 	}
 }
 
-func ParseMemo(swapInfo *tokens.SwapTxInfo, memo string) error {
-	fields := strings.Split(memo, ":")
-	if len(fields) == 2 {
-		if toChainID, err := common.GetBigIntFromStr(fields[1]); err != nil {
-			return err
-		} else {
-			dstBridge := router.GetBridgeByChainID(toChainID.String())
-			if dstBridge != nil && dstBridge.IsValidAddress(fields[0]) {
-				swapInfo.Bind = fields[0]      // Bind
-				swapInfo.ToChainID = toChainID // ToChainID
-				swapInfo.To = swapInfo.Bind    // To
-				return nil
-			}
-		}
-	}
-	return tokens.ErrTxWithWrongMemo
-}
-
 func (b *Bridge) ParseAmountTotal(messageLog sdk.ABCIMessageLog, swapInfo *tokens.SwapTxInfo) error {
 	value := big.NewInt(0)
 	unit := ""
-	for index, event := range messageLog.Events {
-		if event.Type == TransferType {
-			if len(event.Attributes) == 2 {
-				b.ParseCoinAmount(value, swapInfo, messageLog.Events[index-1].Attributes[1], event.Attributes[0], event.Attributes[1], &unit)
-			} else if len(event.Attributes) == 3 {
-				b.ParseCoinAmount(value, swapInfo, event.Attributes[1], event.Attributes[0], event.Attributes[2], &unit)
-			}
-		}
+	for _, transfer := range GetTransfers(messageLog) {
+		b.ParseCoinAmount(value, swapInfo, transfer.Sender, transfer.Recipient, transfer.Amount, &unit)
 	}
 	if value.Cmp(big.NewInt(0)) > 0 {
 		swapInfo.Value = value
@@ -208,25 +220,17 @@ func (b *Bridge) ParseAmountTotal(messageLog sdk.ABCIMessageLog, swapInfo *token
 	return tokens.ErrDepositNotFound
 }
 
-func (b *Bridge) ParseCoinAmount(value *big.Int, swapInfo *tokens.SwapTxInfo, sender, recipient, amount sdk.Attribute, unit *string) {
-	if !(sender.Key == "sender" &&
-		recipient.Key == "recipient" &&
-		amount.Key == "amount") {
-		// key mismatch
-		log.Error("parse coins failed: key mismatch!")
-		return
-	}
-
-	recvCoins, err := ParseCoinsNormalized(amount.Value)
+func (b *Bridge) ParseCoinAmount(value *big.Int, swapInfo *tokens.SwapTxInfo, sender, recipient, amount string, unit *string) {
+	recvCoins, err := ParseCoinsNormalized(amount)
 	if err != nil || len(recvCoins) == 0 {
-		log.Error("parse coins failed", "amount", amount.Value, "err", err)
+		log.Error("parse coins failed", "amount", amount, "err", err)
 		return
 	}
 
 	if *unit != "" {
 		denom := *unit
 		mpc := b.GetRouterContract(denom)
-		if !common.IsEqualIgnoreCase(recipient.Value, mpc) {
+		if !common.IsEqualIgnoreCase(recipient, mpc) {
 			// receiver mismatch
 			return
 		}
@@ -246,9 +250,9 @@ func (b *Bridge) ParseCoinAmount(value *big.Int, swapInfo *tokens.SwapTxInfo, se
 			continue
 		}
 		mpc := b.GetRouterContract(denom)
-		if !common.IsEqualIgnoreCase(recipient.Value, mpc) {
+		if !common.IsEqualIgnoreCase(recipient, mpc) {
 			// receiver mismatch
-			log.Debug("parse coin receiver mismatch", "denom", denom, "have", recipient.Value, "want", mpc)
+			log.Debug("parse coin receiver mismatch", "denom", denom, "have", recipient, "want", mpc)
 			continue
 		}
 		recvAmount := recvCoins.AmountOfNoDenomValidation(denom)
@@ -259,7 +263,7 @@ func (b *Bridge) ParseCoinAmount(value *big.Int, swapInfo *tokens.SwapTxInfo, se
 		*unit = denom
 		value.Add(value, recvAmount.BigInt())
 		if swapInfo.From == "" {
-			swapInfo.From = sender.Value
+			swapInfo.From = sender
 		}
 		break
 	}
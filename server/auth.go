@@ -1,10 +1,15 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -14,19 +19,45 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// paths which are accessible without session token
+var publicPaths = make(map[string]bool)
+
+type sessionTokenKey struct{}
+
+// getSessionToken get the authenticated session token of request, nil if auth is disabled
+func getSessionToken(r *http.Request) *config.SessionToken {
+	tok, _ := r.Context().Value(sessionTokenKey{}).(*config.SessionToken)
+	return tok
+}
+
+const (
+	maxNonceLength      = 64
+	maxAuthedBodyLength = 1024 * 1024 * 10 // 10M
+)
+
+var defaultAuthConfig = &config.AuthConfig{MaxDelaySeconds: 120, MaxFutureSeconds: 10}
+
 func addAuthenticationMiddleware(router *mux.Router) {
 	tokCount := len(config.GetServerConfig().SessionTokens)
 	if tokCount == 0 {
 		return
 	}
-	amw := authenticationMiddleware{authedTokens: make(map[string]*config.SessionToken)}
+	amw := authenticationMiddleware{
+		authedTokens: make(map[string]*config.SessionToken),
+		usedNonces:   make(map[string]map[string]uint64),
+	}
 	amw.Populate()
 	router.Use(amw.Middleware)
-	log.Info("enable auth session token", "tokens", tokCount)
+	log.Info("enable auth session token", "tokens", tokCount, "rejectLegacy", amw.cfg.RejectLegacySignature)
 }
 
 type authenticationMiddleware struct {
 	authedTokens map[string]*config.SessionToken
+	cfg          *config.AuthConfig
+
+	// key is session token, nonce (or timestamp of legacy signature), value is expire time (milli seconds)
+	usedNonces map[string]map[string]uint64
+	nonceLock  sync.Mutex
 }
 
 func (amw *authenticationMiddleware) Populate() {
@@ -34,14 +65,25 @@ func (amw *authenticationMiddleware) Populate() {
 	for _, tok := range cfg.SessionTokens {
 		amw.authedTokens[tok.Token] = tok
 	}
+	amw.cfg = cfg.AuthConfig
+	if amw.cfg == nil {
+		amw.cfg = defaultAuthConfig
+	}
 }
 
-// Middleware function, which will be called for each request
+// Middleware function, which will be called for each request.
+// the session token header is `token:timestamp:nonce:signature`,
+// or the legacy format `token:timestamp:signature` (without nonce and body hash).
 func (amw *authenticationMiddleware) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		sessToken := r.Header.Get("X-Session-Token")
 		parts := strings.Split(sessToken, ":")
-		if len(parts) != 3 {
+		if len(parts) != 3 && len(parts) != 4 {
 			log.Debug("rpc call with wrong token", "token", sessToken)
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
@@ -49,19 +91,46 @@ func (amw *authenticationMiddleware) Middleware(next http.Handler) http.Handler
 
 		token := parts[0]
 		timestamp := parts[1]
-		signature := parts[2]
-		log.Debug("rpc call with sig", "token", token, "timestamp", timestamp, "signature", signature)
-
-		statTotalCalls(token)
+		signature := parts[len(parts)-1]
+		var nonce string
+		if len(parts) == 4 {
+			nonce = parts[2]
+		}
+		log.Debug("rpc call with sig", "token", token, "timestamp", timestamp, "nonce", nonce, "signature", signature)
 
 		tokinfo, ok := amw.authedTokens[token]
 		if !ok {
+			statTotalCalls(unknownTokenStatKey)
 			log.Debug("rpc call with unauth token", "token", token)
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
 
-		if err := verifySignature(tokinfo, timestamp, signature); err != nil {
+		statTotalCalls(token)
+
+		msg := &signMessage{
+			User: tokinfo.User,
+			Salt: tokinfo.Salt,
+			Time: timestamp,
+		}
+		if nonce == "" {
+			if amw.cfg.RejectLegacySignature {
+				log.Debug("rpc call with legacy signature", "token", token)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+		} else {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxAuthedBodyLength))
+			if err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			msg.Nonce = nonce
+			msg.Body = common.Keccak256Hash(body).Hex()
+		}
+
+		if err := amw.verifySignature(tokinfo, msg, signature); err != nil {
 			log.Debug("rpc call verify token failed", "token", token, "err", err)
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
@@ -69,41 +138,47 @@ func (amw *authenticationMiddleware) Middleware(next http.Handler) http.Handler
 
 		statSucessCalls(token)
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionTokenKey{}, tokinfo)))
 	})
 }
 
-// use milli seconds unit, accept recent two minutes
-func verifyTimestamp(timestamp string) error {
+// use milli seconds unit, accept recent timestamps in the configed windows
+func (amw *authenticationMiddleware) verifyTimestamp(timestamp string) (uint64, error) {
 	ts, err := common.GetUint64FromStr(timestamp)
 	if err != nil {
-		return fmt.Errorf("wrong timestamp format. %w", err)
+		return 0, fmt.Errorf("wrong timestamp format. %w", err)
 	}
 
-	const future = uint64(10 * 1000)
-	const delay = uint64(2 * 60 * 1000)
+	future := amw.cfg.MaxFutureSeconds * 1000
+	delay := amw.cfg.MaxDelaySeconds * 1000
 
 	now := uint64(common.NowMilli())
 
 	if ts > now+future {
-		return fmt.Errorf("future timestamp")
+		return 0, fmt.Errorf("future timestamp")
 	}
 	if ts+delay < now {
-		return fmt.Errorf("expired timestamp")
+		return 0, fmt.Errorf("expired timestamp")
 	}
-	return nil
+	return ts + delay, nil
 }
 
 type signMessage struct {
-	User string `json:"user"`
-	Salt string `json:"salt"`
-	Time string `json:"time"`
+	User  string `json:"user"`
+	Salt  string `json:"salt"`
+	Time  string `json:"time"`
+	Nonce string `json:"nonce,omitempty"`
+	Body  string `json:"body,omitempty"` // hex of keccak256 hash of request body
 }
 
-func verifySignature(tok *config.SessionToken, timestamp, sig string) error {
-	if err := verifyTimestamp(timestamp); err != nil {
+func (amw *authenticationMiddleware) verifySignature(tok *config.SessionToken, msg *signMessage, sig string) error {
+	expireAt, err := amw.verifyTimestamp(msg.Time)
+	if err != nil {
 		return err
 	}
+	if len(msg.Nonce) > maxNonceLength {
+		return fmt.Errorf("nonce is too long")
+	}
 
 	signature := common.FromHex(sig)
 	if len(signature) == crypto.SignatureLength {
@@ -112,19 +187,47 @@ func verifySignature(tok *config.SessionToken, timestamp, sig string) error {
 		return fmt.Errorf("wrong signature length")
 	}
 
-	msg, err := json.Marshal(&signMessage{
-		User: tok.User,
-		Salt: tok.Salt,
-		Time: timestamp,
-	})
+	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("marshal sign message failed. %w", err)
 	}
-	hash := common.Keccak256Hash(msg).Bytes()
+	hash := common.Keccak256Hash(data).Bytes()
 
 	pubkey := common.FromHex(tok.Token)
 	if !crypto.VerifySignature(pubkey, hash, signature) {
 		return fmt.Errorf("verify signature failed")
 	}
+
+	// the signed message of legacy signature is unique by timestamp
+	nonce := msg.Nonce
+	if nonce == "" {
+		nonce = "time:" + msg.Time
+	}
+	if !amw.useNonce(tok.Token, nonce, expireAt) {
+		log.Warn("rpc call replay detected", "token", tok.Token, "user", tok.User, "timestamp", msg.Time, "nonce", msg.Nonce)
+		return fmt.Errorf("replayed signature")
+	}
 	return nil
 }
+
+// useNonce record the nonce of token until expired, returns false if it's used
+func (amw *authenticationMiddleware) useNonce(token, nonce string, expireAt uint64) bool {
+	amw.nonceLock.Lock()
+	defer amw.nonceLock.Unlock()
+	nonces, ok := amw.usedNonces[token]
+	if !ok {
+		nonces = make(map[string]uint64)
+		amw.usedNonces[token] = nonces
+	}
+	now := uint64(common.NowMilli())
+	for key, expire := range nonces {
+		if expire < now {
+			delete(nonces, key)
+		}
+	}
+	if _, exist := nonces[nonce]; exist {
+		return false
+	}
+	nonces[nonce] = expireAt
+	return true
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anyswap/CrossChain-Router/v3/common"
+	"github.com/anyswap/CrossChain-Router/v3/tools/crypto"
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+type testSigner struct {
+	key *ecdsa.PrivateKey
+	tok *config.SessionToken
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	return &testSigner{
+		key: key,
+		tok: &config.SessionToken{
+			Token: common.ToHex(crypto.FromECDSAPub(&key.PublicKey)),
+			User:  "user1",
+			Salt:  "11111",
+		},
+	}
+}
+
+func (s *testSigner) sign(t *testing.T, msg *signMessage) string {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal sign message failed: %v", err)
+	}
+	sig, err := crypto.Sign(common.Keccak256Hash(data).Bytes(), s.key)
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	return common.ToHex(sig)
+}
+
+// header session token header, legacy format if nonce is empty
+func (s *testSigner) header(t *testing.T, timestamp, nonce string, body []byte) string {
+	t.Helper()
+	msg := &signMessage{User: s.tok.User, Salt: s.tok.Salt, Time: timestamp}
+	if nonce == "" {
+		return strings.Join([]string{s.tok.Token, timestamp, s.sign(t, msg)}, ":")
+	}
+	msg.Nonce = nonce
+	msg.Body = common.Keccak256Hash(body).Hex()
+	return strings.Join([]string{s.tok.Token, timestamp, nonce, s.sign(t, msg)}, ":")
+}
+
+func newTestAuthMiddleware(cfg *config.AuthConfig, toks ...*config.SessionToken) *authenticationMiddleware {
+	amw := &authenticationMiddleware{
+		authedTokens: make(map[string]*config.SessionToken),
+		usedNonces:   make(map[string]map[string]uint64),
+		cfg:          cfg,
+	}
+	for _, tok := range toks {
+		amw.authedTokens[tok.Token] = tok
+	}
+	return amw
+}
+
+func nowMilliStr(offsetMilli int64) string {
+	return fmt.Sprintf("%d", common.NowMilli()+offsetMilli)
+}
+
+func TestVerifyTimestamp(t *testing.T) {
+	amw := newTestAuthMiddleware(&config.AuthConfig{MaxDelaySeconds: 120, MaxFutureSeconds: 10})
+	tests := []struct {
+		name      string
+		timestamp string
+		valid     bool
+	}{
+		{"now", nowMilliStr(0), true},
+		{"recent past", nowMilliStr(-110 * 1000), true},
+		{"expired", nowMilliStr(-130 * 1000), false},
+		{"near future", nowMilliStr(5 * 1000), true},
+		{"far future", nowMilliStr(20 * 1000), false},
+		{"seconds unit", fmt.Sprintf("%d", common.Now()), false},
+		{"not number", "abc", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		expireAt, err := amw.verifyTimestamp(test.timestamp)
+		if test.valid && err != nil {
+			t.Errorf("%v: verify timestamp failed: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: verify wrong timestamp success", test.name)
+		}
+		if test.valid && err == nil {
+			ts, _ := common.GetUint64FromStr(test.timestamp)
+			if expireAt != ts+120*1000 {
+				t.Errorf("%v: expire time mismatch, have %v want %v", test.name, expireAt, ts+120*1000)
+			}
+		}
+	}
+}
+
+func TestUseNonce(t *testing.T) {
+	amw := newTestAuthMiddleware(defaultAuthConfig)
+	now := uint64(common.NowMilli())
+	future := now + 60*1000
+
+	if !amw.useNonce("token1", "nonce1", future) {
+		t.Fatal("use new nonce failed")
+	}
+	if amw.useNonce("token1", "nonce1", future) {
+		t.Fatal("replayed nonce is accepted")
+	}
+	if !amw.useNonce("token2", "nonce1", future) {
+		t.Fatal("nonce of other token is rejected")
+	}
+	if !amw.useNonce("token1", "nonce2", now-1) {
+		t.Fatal("use new nonce failed")
+	}
+	// expired nonces are removed, then can be used again
+	if !amw.useNonce("token1", "nonce2", future) {
+		t.Fatal("expired nonce is not removed")
+	}
+	if _, exist := amw.usedNonces["token1"]["nonce1"]; !exist {
+		t.Fatal("not expired nonce is removed")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+	body := []byte(`{"method":"bridge.GetServerInfo"}`)
+	bodyHash := common.Keccak256Hash(body).Hex()
+
+	newMsg := func(timestamp, nonce string) *signMessage {
+		msg := &signMessage{User: signer.tok.User, Salt: signer.tok.Salt, Time: timestamp, Nonce: nonce}
+		if nonce != "" {
+			msg.Body = bodyHash
+		}
+		return msg
+	}
+
+	amw := newTestAuthMiddleware(defaultAuthConfig, signer.tok)
+	ts := nowMilliStr(0)
+	validMsg := newMsg(ts, "nonce1")
+	validSig := signer.sign(t, validMsg)
+	legacyMsg := newMsg(ts, "")
+	legacySig := signer.sign(t, legacyMsg)
+	expiredMsg := newMsg(nowMilliStr(-200*1000), "nonce2")
+	longNonceMsg := newMsg(ts, strings.Repeat("n", maxNonceLength+1))
+	tamperedMsg := newMsg(ts, "nonce3")
+	tamperedSig := signer.sign(t, tamperedMsg)
+	tamperedMsg.Body = common.Keccak256Hash([]byte("other body")).Hex()
+	otherMsg := newMsg(ts, "nonce4")
+
+	tests := []struct {
+		name  string
+		msg   *signMessage
+		sig   string
+		valid bool
+	}{
+		{"valid", validMsg, validSig, true},
+		{"replay", validMsg, validSig, false},
+		{"legacy", legacyMsg, legacySig, true},
+		{"legacy replay", legacyMsg, legacySig, false},
+		{"expired", expiredMsg, signer.sign(t, expiredMsg), false},
+		{"too long nonce", longNonceMsg, signer.sign(t, longNonceMsg), false},
+		{"tampered body", tamperedMsg, tamperedSig, false},
+		{"other signer", otherMsg, other.sign(t, otherMsg), false},
+		{"short signature", newMsg(ts, "nonce5"), "0x1234", false},
+		{"64 bytes signature", newMsg(ts, "nonce6"), signer.sign(t, newMsg(ts, "nonce6"))[:2+64*2], true},
+	}
+	for _, test := range tests {
+		err := amw.verifySignature(signer.tok, test.msg, test.sig)
+		if test.valid && err != nil {
+			t.Errorf("%v: verify signature failed: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: verify wrong signature success", test.name)
+		}
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	signer := newTestSigner(t)
+	unknown := newTestSigner(t)
+	body := []byte(`{"jsonrpc":"2.0","method":"bridge.GetServerInfo","params":[],"id":1}`)
+
+	tests := []struct {
+		name         string
+		rejectLegacy bool
+		header       func() string
+		body         []byte
+		status       int
+	}{
+		{"4-part header", false, func() string { return signer.header(t, nowMilliStr(0), "nonce1", body) }, body, http.StatusOK},
+		{"4-part header with tampered body", false, func() string { return signer.header(t, nowMilliStr(0), "nonce2", body) }, []byte(`{}`), http.StatusForbidden},
+		{"legacy 3-part header", false, func() string { return signer.header(t, nowMilliStr(0), "", body) }, body, http.StatusOK},
+		{"legacy 3-part header rejected", true, func() string { return signer.header(t, nowMilliStr(0), "", body) }, body, http.StatusForbidden},
+		{"4-part header with reject legacy", true, func() string { return signer.header(t, nowMilliStr(0), "nonce3", body) }, body, http.StatusOK},
+		{"unknown token", false, func() string { return unknown.header(t, nowMilliStr(0), "nonce4", body) }, body, http.StatusForbidden},
+		{"expired timestamp", false, func() string { return signer.header(t, nowMilliStr(-200*1000), "nonce5", body) }, body, http.StatusForbidden},
+		{"wrong parts", false, func() string { return signer.tok.Token + ":" + nowMilliStr(0) }, body, http.StatusForbidden},
+		{"no header", false, func() string { return "" }, body, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		amw := newTestAuthMiddleware(&config.AuthConfig{
+			MaxDelaySeconds:       120,
+			MaxFutureSeconds:      10,
+			RejectLegacySignature: test.rejectLegacy,
+		}, signer.tok)
+		var authed *config.SessionToken
+		handler := amw.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authed = getSessionToken(r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewReader(test.body))
+		if header := test.header(); header != "" {
+			req.Header.Set("X-Session-Token", header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("%v: status mismatch, have %v want %v", test.name, w.Code, test.status)
+		}
+		if test.status == http.StatusOK && authed != signer.tok {
+			t.Errorf("%v: session token is not set in context", test.name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	routersdk "github.com/anyswap/RouterSDK-injective/sdk"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// initHealth serve liveness and readiness probes
+func initHealth(r *mux.Router) {
+	r.HandleFunc(livenessPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeHealthReport(w, routersdk.CheckLiveness())
+	}).Methods(http.MethodGet)
+	r.HandleFunc(readinessPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeHealthReport(w, routersdk.CheckReadiness())
+	}).Methods(http.MethodGet)
+	publicPaths[livenessPath] = true
+	publicPaths[readinessPath] = true
+}
+
+// writeHealthReport response status is 200 if ok, otherwise 503
+func writeHealthReport(w http.ResponseWriter, report *routersdk.HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.OK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Debug("write health report failed", "err", err)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/rpc/v2"
+	rpcjson "github.com/gorilla/rpc/v2/json2"
+
+	"github.com/anyswap/RouterSDK-injective/config"
+	"github.com/anyswap/RouterSDK-injective/metrics"
+)
+
+const metricsPath = "/metrics"
+
+type requestStartKey struct{}
+
+// initMetrics observe rpc requests (metrics and stats), and serve metrics if enabled
+func initMetrics(r *mux.Router, rpcserver *rpc.Server) {
+	rpcserver.RegisterInterceptFunc(func(i *rpc.RequestInfo) *http.Request {
+		return i.Request.WithContext(context.WithValue(i.Request.Context(), requestStartKey{}, time.Now()))
+	})
+	rpcserver.RegisterAfterFunc(func(i *rpc.RequestInfo) {
+		start, ok := i.Request.Context().Value(requestStartKey{}).(time.Time)
+		if !ok {
+			return
+		}
+		metrics.ObserveRPCRequest(i.Method, rpcErrorCode(i.Error), time.Since(start))
+
+		var token string
+		if tok := getSessionToken(i.Request); tok != nil {
+			token = tok.Token
+		}
+		statMethodCalls(token, i.Method, i.Error)
+	})
+
+	if cfg := config.GetServerConfig().MetricsConfig; cfg != nil && cfg.Enable {
+		r.Handle(metricsPath, metrics.Handler()).Methods(http.MethodGet)
+		if cfg.Public {
+			publicPaths[metricsPath] = true
+		}
+	}
+}
+
+// rpcErrorCode the json rpc error code of response, 0 if success
+func rpcErrorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var jsonErr *rpcjson.Error
+	if errors.As(err, &jsonErr) {
+		return int(jsonErr.Code)
+	}
+	return int(rpcjson.E_SERVER)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/config"
+)
+
+var (
+	errPermissionDenied = errors.New("permission denied")
+
+	roleLevels = map[string]int{
+		config.RoleReadOnly: 1,
+		config.RoleVerifier: 2,
+		config.RoleSigner:   3,
+		config.RoleAdmin:    4,
+	}
+
+	// required role of rpc methods, methods not listed require admin role
+	methodRoles = map[string]string{
+		"GetServerInfo":           config.RoleReadOnly,
+		"GetVersionInfo":          config.RoleReadOnly,
+		"GetTransaction":          config.RoleReadOnly,
+		"GetTransactionStatus":    config.RoleReadOnly,
+		"GetLatestBlockNumber":    config.RoleReadOnly,
+		"GetBalance":              config.RoleReadOnly,
+		"IsValidAddress":          config.RoleReadOnly,
+		"PublicKeyToAddress":      config.RoleReadOnly,
+		"GetMPCAddress":           config.RoleReadOnly,
+		"GetPoolNonce":            config.RoleReadOnly,
+		"GetAccountCache":         config.RoleReadOnly,
+		"GetSequenceReservations": config.RoleReadOnly,
+		"GetBroadcastReport":      config.RoleReadOnly,
+		"GetGatewayStatus":        config.RoleReadOnly,
+		"GetSubscriptionStatus":   config.RoleReadOnly,
+		"GetRecentDeposits":       config.RoleReadOnly,
+
+		"RegisterSwap":      config.RoleVerifier,
+		"VerifyTransaction": config.RoleVerifier,
+		"VerifyBatchSwap":   config.RoleVerifier,
+		"VerifyMsgHash":     config.RoleVerifier,
+
+		"BuildRawTransaction":      config.RoleSigner,
+		"BuildBatchRawTransaction": config.RoleSigner,
+		"MPCSignTransaction":       config.RoleSigner,
+		"SendTransaction":          config.RoleSigner,
+		"SendTransactionAndWait":   config.RoleSigner,
+
+		"GetStatInfo":     config.RoleAdmin,
+		"ReleaseSequence": config.RoleAdmin,
+	}
+)
+
+// validateRequest reject the rpc call if the session token has no permission of the method
+func validateRequest(i *rpc.RequestInfo, _ interface{}) error {
+	tok := getSessionToken(i.Request)
+	if tok == nil {
+		return nil // auth is disabled
+	}
+	method := i.Method[strings.LastIndex(i.Method, ".")+1:]
+	if hasPermission(tok, method) {
+		return nil
+	}
+	log.Warn("rpc call permission denied", "user", tok.User, "roles", tok.GetRoles(), "method", method)
+	return errPermissionDenied
+}
+
+func hasPermission(tok *config.SessionToken, method string) bool {
+	for _, allowed := range tok.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+	required, ok := methodRoles[method]
+	if !ok {
+		required = config.RoleAdmin
+	}
+	for _, role := range tok.GetRoles() {
+		if roleLevels[role] >= roleLevels[required] {
+			return true
+		}
+	}
+	return false
+}
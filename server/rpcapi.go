@@ -6,6 +6,7 @@ import (
 	"errors"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/log"
 	"github.com/anyswap/CrossChain-Router/v3/tokens"
@@ -19,6 +20,8 @@ var (
 	errWrongArgs         = errors.New("wrong args")
 )
 
+const defaultCommitTimeoutSeconds = 60
+
 // ChainSupportAPI rpc api handler
 type ChainSupportAPI struct{}
 
@@ -49,15 +52,21 @@ func (s *ChainSupportAPI) GetVersionInfo(r *http.Request, args *RPCNullArgs, res
 	return nil
 }
 
-// GetStatInfo api
-func (s *ChainSupportAPI) GetStatInfo(r *http.Request, sessToken *string, result *map[uint64]*StatInfo) error {
-	*result = getStatMap(*sessToken)
+// GetStatInfo api (admin role), get rpc call stats of session token or user (all if empty)
+func (s *ChainSupportAPI) GetStatInfo(r *http.Request, filter *string, result *[]*UserStatInfo) error {
+	*result = rpcStats.query(*filter)
 	return nil
 }
 
+// RegisterSwapResult register swap result, same as the router's but with json swap memo extra
+type RegisterSwapResult struct {
+	SwapTxInfos []*routersdk.SwapTxInfoWithExtra
+	Errs        []string
+}
+
 // RegisterSwap register swap.
 // used in `RegisterRouterSwap` server rpc.
-func (b *ChainSupportAPI) RegisterSwap(r *http.Request, args *[]interface{}, result *wrapper.RegisterSwapResult) error {
+func (b *ChainSupportAPI) RegisterSwap(r *http.Request, args *[]interface{}, result *RegisterSwapResult) error {
 	if !routersdk.BridgeInited {
 		return errBridgeNotInited
 	}
@@ -73,16 +82,21 @@ func (b *ChainSupportAPI) RegisterSwap(r *http.Request, args *[]interface{}, res
 	if err != nil {
 		return err
 	}
-	txinfos, errs := routersdk.BridgeInstance.RegisterSwap(txhash, &registerArgs)
-	*result = wrapper.RegisterSwapResult{
+	txinfos, errs := routersdk.BridgeInstance.RegisterSwapWithExtra(r.Context(), txhash, &registerArgs)
+	*result = RegisterSwapResult{
 		SwapTxInfos: txinfos,
-		Errs:        errs,
+		Errs:        make([]string, len(errs)),
+	}
+	for i, err := range errs {
+		if err != nil {
+			result.Errs[i] = err.Error()
+		}
 	}
 	return nil
 }
 
 // VerifyTransaction verify swap tx is valid and success on chain with needed confirmations.
-func (b *ChainSupportAPI) VerifyTransaction(r *http.Request, args *[]interface{}, result *tokens.SwapTxInfo) error {
+func (b *ChainSupportAPI) VerifyTransaction(r *http.Request, args *[]interface{}, result *routersdk.SwapTxInfoWithExtra) error {
 	if !routersdk.BridgeInited {
 		return errBridgeNotInited
 	}
@@ -98,7 +112,7 @@ func (b *ChainSupportAPI) VerifyTransaction(r *http.Request, args *[]interface{}
 	if err != nil {
 		return err
 	}
-	txinfo, err := routersdk.BridgeInstance.VerifyTransaction(txhash, &verifyArgs)
+	txinfo, err := routersdk.BridgeInstance.VerifyTransactionWithExtra(r.Context(), txhash, &verifyArgs)
 	if err != nil {
 		return err
 	}
@@ -119,7 +133,7 @@ func (b *ChainSupportAPI) BuildRawTransaction(r *http.Request, args *[]interface
 	if err != nil {
 		return err
 	}
-	rawTx, err := routersdk.BridgeInstance.BuildRawTransaction(&buildArgs)
+	rawTx, err := routersdk.BridgeInstance.BuildRawTransactionWithContext(r.Context(), &buildArgs)
 	if err != nil {
 		return err
 	}
@@ -130,6 +144,70 @@ func (b *ChainSupportAPI) BuildRawTransaction(r *http.Request, args *[]interface
 	return nil
 }
 
+// BuildBatchTxResult build batch tx result (include modified extra info of each swap)
+type BuildBatchTxResult struct {
+	RawTx  interface{}         `json:"tx"`
+	Extras []*tokens.AllExtras `json:"extras,omitempty"`
+}
+
+// BuildBatchRawTransaction build one tx of several swaps with specified args list.
+// the batch tx is signed by `MPCSignTransaction` with any of the build args.
+func (b *ChainSupportAPI) BuildBatchRawTransaction(r *http.Request, args *[]interface{}, result *BuildBatchTxResult) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) == 0 {
+		return errWrongNumberOfArgs
+	}
+	argsList := make([]*tokens.BuildTxArgs, 0, len(*args))
+	for _, arg := range *args {
+		var buildArgs tokens.BuildTxArgs
+		err := convertToArgument(&buildArgs, arg)
+		if err != nil {
+			return err
+		}
+		argsList = append(argsList, &buildArgs)
+	}
+	rawTx, err := routersdk.BridgeInstance.BuildBatchRawTransactionWithContext(r.Context(), argsList)
+	if err != nil {
+		return err
+	}
+	extras := make([]*tokens.AllExtras, 0, len(argsList))
+	for _, buildArgs := range argsList {
+		extras = append(extras, buildArgs.Extra)
+	}
+	*result = BuildBatchTxResult{
+		RawTx:  rawTx,
+		Extras: extras,
+	}
+	return nil
+}
+
+// VerifyBatchSwap verify the swap (specified by build args) is delivered by the batch tx.
+func (b *ChainSupportAPI) VerifyBatchSwap(r *http.Request, args *[]interface{}, result *bool) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) != 2 {
+		return errWrongNumberOfArgs
+	}
+	txhash, ok := (*args)[0].(string)
+	if !ok {
+		return errWrongArgs
+	}
+	var buildArgs tokens.BuildTxArgs
+	err := convertToArgument(&buildArgs, (*args)[1])
+	if err != nil {
+		return err
+	}
+	err = routersdk.BridgeInstance.VerifyBatchSwapWithContext(r.Context(), txhash, &buildArgs)
+	if err != nil {
+		return err
+	}
+	*result = true
+	return nil
+}
+
 func (b *ChainSupportAPI) VerifyMsgHash(r *http.Request, args *[]interface{}, result *bool) error {
 	if !routersdk.BridgeInited {
 		return errBridgeNotInited
@@ -147,7 +225,7 @@ func (b *ChainSupportAPI) VerifyMsgHash(r *http.Request, args *[]interface{}, re
 	if err != nil {
 		return err
 	}
-	err = routersdk.BridgeInstance.VerifyMsgHash(&rawTx, msgHash)
+	err = routersdk.BridgeInstance.VerifyMsgHashWithContext(r.Context(), &rawTx, msgHash)
 	if err != nil {
 		return err
 	}
@@ -173,7 +251,7 @@ func (b *ChainSupportAPI) MPCSignTransaction(r *http.Request, args *[]interface{
 	if err != nil {
 		return err
 	}
-	signedTx, txHash, err := routersdk.BridgeInstance.MPCSignTransaction(&rawTx, &buildArgs)
+	signedTx, txHash, err := routersdk.BridgeInstance.MPCSignTransactionWithContext(r.Context(), &rawTx, &buildArgs)
 	if err != nil {
 		return err
 	}
@@ -194,7 +272,7 @@ func (b *ChainSupportAPI) SendTransaction(r *http.Request, args *string, result
 	if err != nil {
 		return err
 	}
-	txhash, err := routersdk.BridgeInstance.SendTransaction(txBytes)
+	txhash, err := routersdk.BridgeInstance.SendTransactionWithContext(r.Context(), txBytes)
 	if err != nil {
 		return err
 	}
@@ -202,13 +280,45 @@ func (b *ChainSupportAPI) SendTransaction(r *http.Request, args *string, result
 	return nil
 }
 
+// SendTransactionAndWait send signed raw tx and wait until it is included in block.
+// args is `[signedTx, timeoutSeconds]`, the timeout is optional (default is 60 seconds).
+func (b *ChainSupportAPI) SendTransactionAndWait(r *http.Request, args *[]interface{}, result *routersdk.TxCommitResult) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) != 1 && len(*args) != 2 {
+		return errWrongNumberOfArgs
+	}
+	encodeTx, ok := (*args)[0].(string)
+	if !ok {
+		return errWrongArgs
+	}
+	txBytes, err := base64.StdEncoding.DecodeString(encodeTx)
+	if err != nil {
+		return err
+	}
+	timeoutSeconds := uint64(defaultCommitTimeoutSeconds)
+	if len(*args) == 2 {
+		if err = convertToArgument(&timeoutSeconds, (*args)[1]); err != nil {
+			return err
+		}
+	}
+	commitResult, err := routersdk.BridgeInstance.SendTransactionAndWait(r.Context(), txBytes, time.Duration(timeoutSeconds)*time.Second)
+	if commitResult != nil {
+		// the tx is included in block, failed execution is indicated by the code
+		*result = *commitResult
+		return nil
+	}
+	return err
+}
+
 // GetTransaction get tx by hash.
 func (b *ChainSupportAPI) GetTransaction(r *http.Request, args *[]string, result *interface{}) error {
 	if len(*args) != 1 {
 		return errWrongNumberOfArgs
 	}
 	txhash := (*args)[0]
-	tx, err := routersdk.BridgeInstance.GetTransaction(txhash)
+	tx, err := routersdk.BridgeInstance.GetTransactionByHash(r.Context(), txhash)
 	if err != nil {
 		return err
 	}
@@ -225,7 +335,7 @@ func (b *ChainSupportAPI) GetTransactionStatus(r *http.Request, args *[]string,
 		return errWrongNumberOfArgs
 	}
 	txhash := (*args)[0]
-	txStatus, err := routersdk.BridgeInstance.GetTransactionStatus(txhash)
+	txStatus, err := routersdk.BridgeInstance.GetTransactionStatusWithContext(r.Context(), txhash)
 	if err != nil {
 		return err
 	}
@@ -236,7 +346,7 @@ func (b *ChainSupportAPI) GetTransactionStatus(r *http.Request, args *[]string,
 // GetLatestBlockNumber get latest block number through gateway urls.
 // used in `GetRouterSwap` server rpc.
 func (b *ChainSupportAPI) GetLatestBlockNumber(r *http.Request, args *RPCNullArgs, result *uint64) error {
-	blockNumber, err := routersdk.BridgeInstance.GetLatestBlockNumber()
+	blockNumber, err := routersdk.BridgeInstance.GetLatestBlockNumberWithContext(r.Context())
 	if err != nil {
 		return err
 	}
@@ -251,7 +361,7 @@ func (b *ChainSupportAPI) GetBalance(r *http.Request, args *[]string, result *bi
 	}
 	address := (*args)[0]
 	denom := routersdk.BridgeInstance.Denom
-	balance, err := routersdk.BridgeInstance.GetDenomBalance(address, denom)
+	balance, err := routersdk.BridgeInstance.GetDenomBalance(r.Context(), address, denom)
 	if err != nil {
 		return err
 	}
@@ -300,10 +410,92 @@ func (b *ChainSupportAPI) GetPoolNonce(r *http.Request, args *[]string, result *
 	}
 	address := (*args)[0]
 	height := (*args)[1]
-	nonce, err := routersdk.BridgeInstance.GetPoolNonce(address, height)
+	nonce, err := routersdk.BridgeInstance.GetPoolNonceWithContext(r.Context(), address, height)
 	if err != nil {
 		return err
 	}
 	*result = nonce
 	return nil
 }
+
+// GetAccountCache get cached account number and sequence (for debugging).
+// args is optional address to filter, get all cached accounts if not specified.
+func (b *ChainSupportAPI) GetAccountCache(r *http.Request, args *[]string, result *[]*routersdk.AccountCacheEntry) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) > 1 {
+		return errWrongNumberOfArgs
+	}
+	var address string
+	if len(*args) == 1 {
+		address = (*args)[0]
+	}
+	*result = routersdk.BridgeInstance.AccountCache.Entries(address)
+	return nil
+}
+
+// GetSequenceReservations get reserved sequences and pending txs of address.
+func (b *ChainSupportAPI) GetSequenceReservations(r *http.Request, args *[]string, result *[]*routersdk.SequenceReservation) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) != 1 {
+		return errWrongNumberOfArgs
+	}
+	*result = routersdk.BridgeInstance.SequenceManager.Reservations((*args)[0])
+	return nil
+}
+
+// ReleaseSequence release reserved sequence of abandoned tx, args is `[address, sequence]`.
+func (b *ChainSupportAPI) ReleaseSequence(r *http.Request, args *[]interface{}, result *bool) error {
+	if !routersdk.BridgeInited {
+		return errBridgeNotInited
+	}
+	if len(*args) != 2 {
+		return errWrongNumberOfArgs
+	}
+	address, ok := (*args)[0].(string)
+	if !ok {
+		return errWrongArgs
+	}
+	var sequence uint64
+	err := convertToArgument(&sequence, (*args)[1])
+	if err != nil {
+		return err
+	}
+	routersdk.BridgeInstance.SequenceManager.Release(address, sequence)
+	*result = true
+	return nil
+}
+
+// GetBroadcastReport get the broadcast results of each endpoint of tx sent by `SendTransaction`.
+func (b *ChainSupportAPI) GetBroadcastReport(r *http.Request, args *[]string, result *routersdk.BroadcastReport) error {
+	if len(*args) != 1 {
+		return errWrongNumberOfArgs
+	}
+	report := routersdk.GetBroadcastReport((*args)[0])
+	if report == nil {
+		return errors.New("broadcast report not found")
+	}
+	*result = *report
+	return nil
+}
+
+// GetGatewayStatus get health status (latency, error rate, lag, availability) of gateway nodes.
+func (b *ChainSupportAPI) GetGatewayStatus(r *http.Request, args *RPCNullArgs, result *[]*routersdk.GatewayStatus) error {
+	*result = routersdk.GetGatewayStatus()
+	return nil
+}
+
+// GetSubscriptionStatus get status of the websocket subscription (endpoint, latest height, deposits count).
+func (b *ChainSupportAPI) GetSubscriptionStatus(r *http.Request, args *RPCNullArgs, result *routersdk.SubscriptionStatus) error {
+	*result = *routersdk.GetSubscriptionStatus()
+	return nil
+}
+
+// GetRecentDeposits get recent txs with transfer to router mpc notified by the websocket subscription.
+func (b *ChainSupportAPI) GetRecentDeposits(r *http.Request, args *RPCNullArgs, result *[]*routersdk.DepositNotification) error {
+	*result = routersdk.GetRecentDeposits()
+	return nil
+}
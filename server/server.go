@@ -25,6 +25,7 @@ import (
 func StartAPIServer() {
 	router := mux.NewRouter()
 	initAPIRouter(router)
+	startStats()
 
 	addAuthenticationMiddleware(router)
 
@@ -94,6 +95,9 @@ func initAPIRouter(r *mux.Router) {
 	if err != nil {
 		log.Fatal("start rpc service failed", "err", err)
 	}
+	rpcserver.RegisterValidateRequestFunc(validateRequest)
+	initMetrics(r, rpcserver)
+	initHealth(r)
 
 	r.Handle("/", rpcserver)
 }
@@ -1,72 +1,283 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
+	"github.com/anyswap/RouterSDK-injective/cmd/utils"
+	"github.com/anyswap/RouterSDK-injective/config"
 )
 
 // StatInfo statistics info
 type StatInfo struct {
-	SuccCount  uint64
-	TotalCount uint64
+	SuccCount     uint64                     // calls passed authentication
+	TotalCount    uint64                     // calls with session token
+	RejectedCount uint64                     // calls without permission of method
+	Methods       map[string]*MethodStatInfo `json:",omitempty"`
 }
 
-var (
-	// key is session token, start date timestamp
-	stats = make(map[string]map[uint64]*StatInfo)
+// MethodStatInfo statistics info of rpc method
+type MethodStatInfo struct {
+	Calls    uint64
+	Errors   uint64
+	Rejected uint64 // calls without permission
+}
+
+// UserStatInfo statistics info of session token
+type UserStatInfo struct {
+	Token string
+	User  string
+	Total *StatInfo
+	Days  map[uint64]*StatInfo // key is start date timestamp
+}
+
+type statStore struct {
+	lock sync.Mutex
+	// key is session token (empty if auth is disabled), start date timestamp
+	stats map[string]map[uint64]*StatInfo
+	dirty bool // changed since last saved
 	// minimum interval to print rpc call stats
 	latestPrintStatsTimestamp uint64
+}
+
+var (
+	rpcStats = &statStore{stats: make(map[string]map[uint64]*StatInfo)}
+
+	defaultStatsConfig = &config.StatsConfig{RetentionDays: 30}
 )
 
 const (
 	secondsPerDay      = 86400
 	printStatsInterval = 28800
-)
 
-func getStatMap(token string) map[uint64]*StatInfo {
-	return stats[token]
-}
+	// calls with unknown session tokens are counted together
+	unknownTokenStatKey = "unknown"
+)
 
-func getOrCreateStatMap(token string) map[uint64]*StatInfo {
-	statMap, ok := stats[token]
-	if !ok {
-		statMap = make(map[uint64]*StatInfo)
-		stats[token] = statMap
+func statsConfig() *config.StatsConfig {
+	if cfg := config.GetServerConfig().StatsConfig; cfg != nil {
+		return cfg
 	}
-	return statMap
+	return defaultStatsConfig
 }
 
 func getDateStartTimestamp(timestamp uint64) uint64 {
 	return timestamp - (timestamp % secondsPerDay)
 }
 
-func getOrCreateCurrStat(token string) *StatInfo {
-	statMap := getOrCreateStatMap(token)
+// update call f with stat of token at current date
+func (s *statStore) update(token string, f func(stat *StatInfo)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	statMap, ok := s.stats[token]
+	if !ok {
+		statMap = make(map[uint64]*StatInfo)
+		s.stats[token] = statMap
+	}
 	currTime := getDateStartTimestamp(uint64(time.Now().Unix()))
 	stat, ok := statMap[currTime]
 	if !ok {
 		stat = &StatInfo{}
 		statMap[currTime] = stat
+		s.prune()
 	}
-	return stat
+	f(stat)
+	s.dirty = true
 }
 
-func statTotalCalls(token string) {
-	stat := getOrCreateCurrStat(token)
-	stat.TotalCount++
-	log.Debug("update rpc call stats", "token", token, "total", stat.TotalCount)
+// prune remove stats older than retention days, must be called with lock held
+func (s *statStore) prune() {
+	retention := statsConfig().RetentionDays * secondsPerDay
+	today := getDateStartTimestamp(uint64(time.Now().Unix()))
+	for token, statMap := range s.stats {
+		for date := range statMap {
+			if date+retention <= today {
+				delete(statMap, date)
+				s.dirty = true
+			}
+		}
+		if len(statMap) == 0 {
+			delete(s.stats, token)
+		}
+	}
+}
+
+// query get stats of session tokens or users in filter (all if empty)
+func (s *statStore) query(filter string) []*UserStatInfo {
+	users := make(map[string]string)
+	for _, tok := range config.GetServerConfig().SessionTokens {
+		users[tok.Token] = tok.User
+	}
 
-	now := uint64(common.Now())
-	if now-latestPrintStatsTimestamp > printStatsInterval {
-		log.Infof("print rpc call stats. %v", common.ToJSONString(stat, false))
-		latestPrintStatsTimestamp = now
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.prune()
+	result := make([]*UserStatInfo, 0, len(s.stats))
+	for token, statMap := range s.stats {
+		user := users[token]
+		if filter != "" && filter != token && filter != user {
+			continue
+		}
+		info := &UserStatInfo{
+			Token: token,
+			User:  user,
+			Total: &StatInfo{},
+			Days:  make(map[uint64]*StatInfo, len(statMap)),
+		}
+		for date, stat := range statMap {
+			info.Days[date] = stat.clone()
+			info.Total.add(stat)
+		}
+		result = append(result, info)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].User != result[j].User {
+			return result[i].User < result[j].User
+		}
+		return result[i].Token < result[j].Token
+	})
+	return result
+}
+
+func (stat *StatInfo) clone() *StatInfo {
+	result := &StatInfo{}
+	result.add(stat)
+	return result
+}
+
+func (stat *StatInfo) add(other *StatInfo) {
+	stat.SuccCount += other.SuccCount
+	stat.TotalCount += other.TotalCount
+	stat.RejectedCount += other.RejectedCount
+	for method, ms := range other.Methods {
+		stat.methodStat(method).add(ms)
+	}
+}
+
+func (stat *StatInfo) methodStat(method string) *MethodStatInfo {
+	if stat.Methods == nil {
+		stat.Methods = make(map[string]*MethodStatInfo)
+	}
+	ms, ok := stat.Methods[method]
+	if !ok {
+		ms = &MethodStatInfo{}
+		stat.Methods[method] = ms
+	}
+	return ms
+}
+
+func (ms *MethodStatInfo) add(other *MethodStatInfo) {
+	ms.Calls += other.Calls
+	ms.Errors += other.Errors
+	ms.Rejected += other.Rejected
+}
+
+func statTotalCalls(token string) {
+	rpcStats.update(token, func(stat *StatInfo) {
+		stat.TotalCount++
+		log.Debug("update rpc call stats", "token", token, "total", stat.TotalCount)
+
+		now := uint64(common.Now())
+		if now-rpcStats.latestPrintStatsTimestamp > printStatsInterval {
+			log.Infof("print rpc call stats. %v", common.ToJSONString(stat, false))
+			rpcStats.latestPrintStatsTimestamp = now
+		}
+	})
 }
 
 func statSucessCalls(token string) {
-	stat := getOrCreateCurrStat(token)
-	stat.SuccCount++
-	log.Debug("update rpc call stats", "token", token, "succ", stat.SuccCount)
+	rpcStats.update(token, func(stat *StatInfo) {
+		stat.SuccCount++
+		log.Debug("update rpc call stats", "token", token, "succ", stat.SuccCount)
+	})
+}
+
+func statMethodCalls(token, method string, err error) {
+	rpcStats.update(token, func(stat *StatInfo) {
+		ms := stat.methodStat(method)
+		ms.Calls++
+		if errors.Is(err, errPermissionDenied) {
+			ms.Rejected++
+			stat.RejectedCount++
+		} else if err != nil {
+			ms.Errors++
+		}
+	})
+}
+
+// startStats load the persisted stats, and save them periodically and on shutdown
+func startStats() {
+	cfg := statsConfig()
+	if cfg.File == "" {
+		return
+	}
+	if err := rpcStats.load(cfg.File); err != nil {
+		log.Fatal("load rpc call stats failed", "file", cfg.File, "err", err)
+	}
+	log.Info("load rpc call stats success", "file", cfg.File, "tokens", len(rpcStats.stats))
+
+	utils.TopWaitGroup.Add(1)
+	go func() {
+		defer utils.TopWaitGroup.Done()
+		ticker := time.NewTicker(time.Duration(cfg.SaveIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-utils.CleanupChan:
+				rpcStats.save(cfg.File)
+				return
+			case <-ticker.C:
+				rpcStats.save(cfg.File)
+			}
+		}
+	}()
+}
+
+func (s *statStore) load(file string) error {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	stats := make(map[string]map[uint64]*StatInfo)
+	if err = json.Unmarshal(data, &stats); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stats = stats
+	s.prune()
+	return nil
+}
+
+// save write to temp file and rename to keep the stats file complete
+func (s *statStore) save(file string) {
+	s.lock.Lock()
+	if !s.dirty {
+		s.lock.Unlock()
+		return
+	}
+	data, err := json.Marshal(s.stats)
+	s.dirty = false
+	s.lock.Unlock()
+	if err == nil {
+		tmpFile := file + ".tmp"
+		if err = os.WriteFile(tmpFile, data, 0o644); err == nil {
+			err = os.Rename(tmpFile, file)
+		}
+	}
+	if err != nil {
+		s.lock.Lock()
+		s.dirty = true
+		s.lock.Unlock()
+		log.Warn("save rpc call stats failed", "file", file, "err", err)
+	}
 }
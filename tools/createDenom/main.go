@@ -62,7 +62,7 @@ func main() {
 
 func initExtra() (*tokens.AllExtras, error) {
 	extra := &tokens.AllExtras{}
-	if account, err := bridge.GetBaseAccount(paramSender); err != nil {
+	if account, err := bridge.GetBaseAccount(routersdk.BaseContext(), paramSender); err != nil {
 		return nil, err
 	} else {
 		if extra.Sequence == nil {
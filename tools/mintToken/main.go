@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -21,6 +22,7 @@ var (
 	paramConfigFile string
 	paramChainID    string
 	paramPrefix     string
+	paramKeyType    string
 	paramSender     string
 	paramDenom      string
 	paramAmount     uint64
@@ -31,6 +33,9 @@ var (
 	paramPublicKey  string
 	paramPrivateKey string
 
+	paramWait        bool
+	paramWaitTimeout = uint64(60)
+
 	chainID   = big.NewInt(0)
 	mpcConfig *mpc.Config
 
@@ -53,17 +58,29 @@ func main() {
 				log.Fatalf("MPCSignTransaction err:%+v", err)
 			}
 		}
+		sendTx(signedTx, txHash)
+	}
+}
+
+func sendTx(signedTx interface{}, txHash string) {
+	if !paramWait {
 		if txHashFromSend, err := bridge.SendTransaction(signedTx); err != nil {
 			log.Fatalf("SendTransaction err:%+v", err)
 		} else {
 			log.Printf("txhash: %+s txHashFromSend: %+s", txHash, txHashFromSend)
 		}
+		return
+	}
+	if result, err := bridge.SendTransactionAndWait(routersdk.BaseContext(), signedTx, time.Duration(paramWaitTimeout)*time.Second); err != nil {
+		log.Fatalf("SendTransactionAndWait err:%+v", err)
+	} else {
+		log.Printf("txhash: %+s height: %v code: %v gasUsed: %v", result.TxHash, result.Height, result.Code, result.GasUsed)
 	}
 }
 
 func initExtra() (*tokens.AllExtras, error) {
 	extra := &tokens.AllExtras{}
-	if account, err := bridge.GetBaseAccount(paramSender); err != nil {
+	if account, err := bridge.GetBaseAccount(routersdk.BaseContext(), paramSender); err != nil {
 		return nil, err
 	} else {
 		if extra.Sequence == nil {
@@ -104,7 +121,7 @@ func BuildTx() (*routersdk.BuildRawTx, error) {
 			txBuilder.SetFeeAmount(fee)
 		}
 		txBuilder.SetGasLimit(*extra.Gas)
-		pubKey, err := routersdk.PubKeyFromStr(paramPublicKey)
+		pubKey, err := bridge.PubKeyFromStr(paramPublicKey)
 		if err != nil {
 			log.Fatalf("PubKeyFromStr error:%+v", err)
 		}
@@ -129,14 +146,14 @@ func BuildTx() (*routersdk.BuildRawTx, error) {
 
 func MPCSignTransaction(tx *routersdk.BuildRawTx, publicKey string) (signedTx interface{}, txHash string, err error) {
 	mpcPubkey := publicKey
-	pubKey, err := routersdk.PubKeyFromStr(mpcPubkey)
+	pubKey, err := bridge.PubKeyFromStr(mpcPubkey)
 	if err != nil {
 		return nil, txHash, err
 	}
 	if signBytes, err := bridge.GetSignBytes(tx); err != nil {
 		return nil, "", err
 	} else {
-		msgHash := fmt.Sprintf("%X", routersdk.Sha256Sum(signBytes))
+		msgHash := fmt.Sprintf("%X", bridge.KeyType.SignHash(signBytes))
 		if keyID, rsvs, err := mpcConfig.DoSignOneEC(mpcPubkey, msgHash, ""); err != nil {
 			return nil, "", err
 		} else {
@@ -185,6 +202,7 @@ func initFlags() {
 	flag.StringVar(&paramConfigFile, "config", "", "config file to init mpc and gateway")
 	flag.StringVar(&paramChainID, "chainID", "", "chain id")
 	flag.StringVar(&paramPrefix, "prefix", "inj", "bech32 prefix for account")
+	flag.StringVar(&paramKeyType, "keyType", "", "key type, eg. secp256k1, ethsecp256k1")
 	flag.StringVar(&paramSender, "sender", "", "token creater")
 	flag.StringVar(&paramDenom, "denom", "", "token denom")
 	flag.Uint64Var(&paramAmount, "amount", paramAmount, "amount")
@@ -194,6 +212,8 @@ func initFlags() {
 	flag.Uint64Var(&paramSequence, "sequence", paramSequence, "sequence number")
 	flag.StringVar(&paramPublicKey, "publicKey", "", "public Key")
 	flag.StringVar(&paramPrivateKey, "privateKey", "", "private key")
+	flag.BoolVar(&paramWait, "wait", paramWait, "wait until the tx is included in block")
+	flag.Uint64Var(&paramWaitTimeout, "waitTimeout", paramWaitTimeout, "timeout seconds of waiting tx included in block")
 
 	flag.Parse()
 
@@ -233,6 +253,12 @@ func initBridge() {
 		ChainID: chainID.String(),
 	})
 
+	keyType, err := routersdk.GetKeyType(paramKeyType)
+	if err != nil {
+		log.Fatal("wrong param keyType", "err", err)
+	}
+	bridge.SetKeyType(keyType)
+
 	config := sdk.GetConfig()
 	config.SetBech32PrefixForAccount(paramPrefix, "")
 	config.Seal()
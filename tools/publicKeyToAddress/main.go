@@ -11,11 +11,13 @@ import (
 var (
 	paramPublicKey string
 	paramPrefix    string
+	paramKeyType   string
 )
 
 func initFlags() {
 	flag.StringVar(&paramPublicKey, "p", "", "publicKey")
 	flag.StringVar(&paramPrefix, "prefix", "inj", "prefix, eg. cosmos, sei, etc.")
+	flag.StringVar(&paramKeyType, "keyType", "", "key type, eg. secp256k1, ethsecp256k1")
 
 	flag.Parse()
 }
@@ -23,7 +25,11 @@ func initFlags() {
 func main() {
 	initFlags()
 
-	if addr, err := routersdk.PublicKeyToAddress(paramPrefix, paramPublicKey); err != nil {
+	keyType, err := routersdk.GetKeyType(paramKeyType)
+	if err != nil {
+		log.Fatalf("err: %v\n", err)
+	}
+	if addr, err := routersdk.PublicKeyToAddressWithKeyType(paramPrefix, keyType, paramPublicKey); err != nil {
 		log.Fatalf("err: %v\n", err)
 	} else {
 		fmt.Printf("addr: %v\n", addr)
@@ -31,9 +31,9 @@ func main() {
 		for _, tx := range res.Block.Data.Txs {
 			if txBytes, err := base64.StdEncoding.DecodeString(tx); err == nil {
 				txHash := fmt.Sprintf("%X", routersdk.Sha256Sum(txBytes))
-				if txRes, err := br.GetTransactionByHash(txHash); err == nil {
+				if txRes, err := br.GetTransactionByHash(routersdk.BaseContext(), txHash); err == nil {
 					if err := ParseMemo(txRes.Tx.Body.Memo); err == nil {
-						if err := ParseAmountTotal(txRes.TxResponse.Logs); err == nil {
+						if err := ParseAmountTotal(txRes.TxResponse.MessageLogs()); err == nil {
 							log.Info("verify txHash success", "txHash", txHash)
 						}
 					}
@@ -45,11 +45,9 @@ func main() {
 
 func ParseAmountTotal(messageLogs []sdk.ABCIMessageLog) (err error) {
 	for _, logDetail := range messageLogs {
-		for _, event := range logDetail.Events {
-			if event.Type == routersdk.TransferType {
-				if (len(event.Attributes) == 2 || len(event.Attributes) == 3) && event.Attributes[0].Value == paramMpc {
-					return nil
-				}
+		for _, transfer := range routersdk.GetTransfers(logDetail) {
+			if transfer.Recipient == paramMpc {
+				return nil
 			}
 		}
 	}
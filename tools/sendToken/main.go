@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/anyswap/CrossChain-Router/v3/common"
 	"github.com/anyswap/CrossChain-Router/v3/log"
@@ -17,6 +18,7 @@ var (
 	paramURLs       string
 	paramChainID    string
 	paramPrefix     string
+	paramKeyType    string
 	paramSender     string
 	paramTo         string
 	paramDenom      string
@@ -29,6 +31,9 @@ var (
 	paramSequence   uint64
 	paramUseGrpc    bool
 
+	paramWait        bool
+	paramWaitTimeout = uint64(60)
+
 	chainID = big.NewInt(0)
 	bridge  = routersdk.NewCrossChainBridge()
 )
@@ -43,18 +48,30 @@ func main() {
 		if signedTx, txHash, err := bridge.SignTransactionWithPrivateKey(rawTx, paramPrivateKey); err != nil {
 			log.Fatalf("SignTransactionWithPrivateKey err:%+v", err)
 		} else {
-			if txHashFromSend, err := bridge.SendTransaction(signedTx); err != nil {
-				log.Fatalf("SendTransaction err:%+v", err)
-			} else {
-				log.Printf("txhash: %+s txHashFromSend: %+s", txHash, txHashFromSend)
-			}
+			sendTx(signedTx, txHash)
+		}
+	}
+}
+
+func sendTx(signedTx interface{}, txHash string) {
+	if !paramWait {
+		if txHashFromSend, err := bridge.SendTransaction(signedTx); err != nil {
+			log.Fatalf("SendTransaction err:%+v", err)
+		} else {
+			log.Printf("txhash: %+s txHashFromSend: %+s", txHash, txHashFromSend)
 		}
+		return
+	}
+	if result, err := bridge.SendTransactionAndWait(routersdk.BaseContext(), signedTx, time.Duration(paramWaitTimeout)*time.Second); err != nil {
+		log.Fatalf("SendTransactionAndWait err:%+v", err)
+	} else {
+		log.Printf("txhash: %+s height: %v code: %v gasUsed: %v", result.TxHash, result.Height, result.Code, result.GasUsed)
 	}
 }
 
 func initExtra() (*tokens.AllExtras, error) {
 	extra := &tokens.AllExtras{}
-	if account, err := bridge.GetBaseAccount(paramSender); err != nil {
+	if account, err := bridge.GetBaseAccount(routersdk.BaseContext(), paramSender); err != nil {
 		return nil, err
 	} else {
 		if extra.Sequence == nil {
@@ -96,7 +113,7 @@ func BuildTx() (*routersdk.BuildRawTx, error) {
 			txBuilder.SetFeeAmount(fee)
 		}
 		txBuilder.SetGasLimit(*extra.Gas)
-		pubKey, err := routersdk.PubKeyFromStr(paramPublicKey)
+		pubKey, err := bridge.PubKeyFromStr(paramPublicKey)
 		if err != nil {
 			log.Fatalf("PubKeyFromStr error:%+v", err)
 		}
@@ -123,6 +140,7 @@ func initFlags() {
 	flag.StringVar(&paramURLs, "url", "https://testnet.tm.injective.network:443", "urls (comma separated)")
 	flag.StringVar(&paramChainID, "chainID", "", "chain id")
 	flag.StringVar(&paramPrefix, "prefix", "inj", "bech32 prefix for account")
+	flag.StringVar(&paramKeyType, "keyType", "", "key type, eg. secp256k1, ethsecp256k1")
 	flag.StringVar(&paramSender, "sender", "", "sender address")
 	flag.StringVar(&paramTo, "to", "", "to address")
 	flag.StringVar(&paramDenom, "denom", "", "denom")
@@ -134,6 +152,8 @@ func initFlags() {
 	flag.StringVar(&paramPrivateKey, "privateKey", "", "private key")
 	flag.StringVar(&paramMemo, "memo", "", "tx memo")
 	flag.BoolVar(&paramUseGrpc, "grpc", paramUseGrpc, "use grpc call")
+	flag.BoolVar(&paramWait, "wait", paramWait, "wait until the tx is included in block")
+	flag.Uint64Var(&paramWaitTimeout, "waitTimeout", paramWaitTimeout, "timeout seconds of waiting tx included in block")
 
 	flag.Parse()
 
@@ -160,6 +180,12 @@ func initBridge() {
 		ChainID: chainID.String(),
 	})
 
+	keyType, err := routersdk.GetKeyType(paramKeyType)
+	if err != nil {
+		log.Fatal("wrong param keyType", "err", err)
+	}
+	bridge.SetKeyType(keyType)
+
 	config := sdk.GetConfig()
 	config.SetBech32PrefixForAccount(paramPrefix, "")
 	config.Seal()